@@ -2,21 +2,34 @@ package stdcli
 
 import (
 	"fmt"
+	"math"
+	"regexp"
 	"strings"
 )
 
 type ColumnWriter interface {
 	Append(items ...any)
 	Print() error
+	Truncate(columns ...int)
+	Wrap(columns ...int)
 }
 
 type columnWriter struct {
-	ctx  Context
-	rows [][]any
+	ctx       *defaultContext
+	minWidths []int
+	rows      [][]any
+	truncate  map[int]bool
+	wrap      map[int]bool
 }
 
 var _ ColumnWriter = &columnWriter{}
 
+// minColumnWidth is the narrowest a truncatable or wrappable column will be
+// shrunk to when fitting the terminal.
+const minColumnWidth = 4
+
+var cellTag = regexp.MustCompile(`^<(/?)([a-zA-Z0-9_-]+)>`)
+
 func (c *columnWriter) Append(items ...any) {
 	c.rows = append(c.rows, items)
 }
@@ -26,34 +39,131 @@ func (c *columnWriter) Print() error {
 		return nil
 	}
 
-	widths := c.widths()
+	limits, widths := c.layout()
 
 	for _, row := range c.rows {
+		c.printRow(row, limits, widths)
+	}
+
+	return nil
+}
+
+// layout returns the most each column may hold and how wide to pad it.
+func (c *columnWriter) layout() ([]int, []int) {
+	limits := c.maxWidths()
+
+	if width := c.ctx.outputWidth(); width > 0 {
+		c.fit(limits, width)
+	}
+
+	widths := c.widths()
+
+	for i := range widths {
+		widths[i] = min(widths[i], limits[i])
+	}
+
+	return limits, widths
+}
+
+func (c *columnWriter) printRow(row []any, limits, widths []int) {
+	cells := make([][]string, len(row))
+	height := 1
+
+	for i, item := range row {
+		limit := math.MaxInt
+
+		if i < len(limits) {
+			limit = limits[i]
+		}
+
+		cells[i] = c.cell(i, fmt.Sprintf("%v", item), limit)
+		height = max(height, len(cells[i]))
+	}
+
+	for line := 0; line < height; line++ {
 		parts := []string{}
-		for i, item := range row {
-			itemStr := fmt.Sprintf("%v", item)
-			strippedLen := len(stripTags(item))
+
+		for i := range row {
+			itemStr := ""
+
+			if line < len(cells[i]) {
+				itemStr = cells[i][line]
+			}
 
 			if i < len(widths) && widths[i] > 0 {
 				// Calculate how many spaces we need to add for padding
-				padding := widths[i] - strippedLen
+				padding := widths[i] - textWidth(itemStr)
 				if padding > 0 {
 					itemStr = itemStr + strings.Repeat(" ", padding)
 				}
 			}
 			parts = append(parts, itemStr)
 		}
-		c.ctx.Writef("<value>%s</value>\n", strings.Join(parts, "  ")) //nolint:errcheck
+		c.ctx.Writef("%s\n", wrapTag("value", strings.Join(parts, "  "))) //nolint:errcheck
 	}
+}
 
-	return nil
+// Truncate marks columns that may be cut short with an ellipsis to fit the
+// terminal width.
+func (c *columnWriter) Truncate(columns ...int) {
+	c.truncate = markColumns(c.truncate, columns)
 }
 
-func (c *columnWriter) widths() []int {
-	if len(c.rows) == 0 {
-		return []int{}
+// Wrap marks columns that may be wrapped onto multiple lines to fit the
+// terminal width.
+func (c *columnWriter) Wrap(columns ...int) {
+	c.wrap = markColumns(c.wrap, columns)
+}
+
+// cell returns the lines for item in column i, truncated or wrapped to limit
+// if the column allows it.
+func (c *columnWriter) cell(i int, item string, limit int) []string {
+	if textWidth(item) <= limit {
+		return []string{item}
+	}
+
+	switch {
+	case c.wrap[i]:
+		return wrapText(item, limit)
+	case c.truncate[i]:
+		return []string{truncateText(item, limit)}
+	default:
+		return []string{item}
 	}
+}
+
+// fit shrinks the widest truncatable or wrappable column one character at a
+// time until a row fits in width or nothing else can shrink.
+func (c *columnWriter) fit(widths []int, width int) {
+	for {
+		total := 2 * (len(widths) - 1)
+
+		for _, w := range widths {
+			total += w
+		}
+
+		if total <= width {
+			return
+		}
+
+		widest := -1
+
+		for i, w := range widths {
+			if (c.truncate[i] || c.wrap[i]) && w > minColumnWidth && (widest == -1 || w > widths[widest]) {
+				widest = i
+			}
+		}
+
+		if widest == -1 {
+			return
+		}
 
+		widths[widest]--
+	}
+}
+
+// maxWidths returns the widest item in each column.
+func (c *columnWriter) maxWidths() []int {
 	// Find the maximum number of columns
 	maxCols := 0
 	for _, row := range c.rows {
@@ -62,17 +172,29 @@ func (c *columnWriter) widths() []int {
 		}
 	}
 
-	widths := make([]int, maxCols)
+	widths := make([]int, max(maxCols, len(c.minWidths)))
+
+	copy(widths, c.minWidths)
 
 	// Calculate max width for each column
 	for _, row := range c.rows {
 		for i, item := range row {
-			if length := len(stripTags(item)); length > widths[i] {
+			if length := textWidth(item); length > widths[i] {
 				widths[i] = length
 			}
 		}
 	}
 
+	return widths
+}
+
+func (c *columnWriter) widths() []int {
+	if len(c.rows) == 0 {
+		return []int{}
+	}
+
+	widths := c.maxWidths()
+
 	// Last column doesn't need padding
 	if len(widths) > 0 {
 		widths[len(widths)-1] = 0
@@ -80,3 +202,164 @@ func (c *columnWriter) widths() []int {
 
 	return widths
 }
+
+func markColumns(marks map[int]bool, columns []int) map[int]bool {
+	if marks == nil {
+		marks = map[int]bool{}
+	}
+
+	for _, i := range columns {
+		marks[i] = true
+	}
+
+	return marks
+}
+
+// cutText splits s after n columns of visible text. Tags that are open at
+// the cut are closed at the end of the head and reopened at the start of the
+// tail, as are ANSI colors.
+func cutText(s string, n int) (string, string) {
+	head := strings.Builder{}
+	open := []string{}
+	sgr := ""
+	i := 0
+
+	for i < len(s) {
+		if _, size := tagEscape(s[i:]); size > 0 {
+			if n < 1 {
+				break
+			}
+
+			head.WriteString(s[i : i+size])
+			i += size
+			n--
+			continue
+		}
+
+		if m := cellTag.FindStringSubmatch(s[i:]); m != nil {
+			closing := m[1] == "/"
+
+			switch {
+			case closing && len(open) > 0 && open[len(open)-1] == m[2]:
+				open = open[:len(open)-1]
+			case !closing && n > 0 && strings.Contains(s[i+len(m[0]):], fmt.Sprintf("</%s>", m[2])):
+				open = append(open, m[2])
+			default:
+				m = nil
+			}
+
+			if m != nil {
+				head.WriteString(m[0])
+				i += len(m[0])
+				continue
+			}
+		}
+
+		if loc := ansiMatcher.FindStringIndex(s[i:]); loc != nil && loc[0] == 0 {
+			seq := s[i : i+loc[1]]
+
+			switch {
+			case seq == "\033[0m" || seq == "\033[m":
+				sgr = ""
+			case strings.HasSuffix(seq, "m"):
+				sgr += seq
+			}
+
+			head.WriteString(seq)
+			i += len(seq)
+			continue
+		}
+
+		size, width := nextCluster(s[i:])
+
+		if width > n {
+			break
+		}
+
+		head.WriteString(s[i : i+size])
+		i += size
+		n -= width
+	}
+
+	tail := strings.Builder{}
+
+	if sgr != "" && i < len(s) {
+		head.WriteString("\033[0m")
+	}
+
+	for j := len(open) - 1; j >= 0; j-- {
+		fmt.Fprintf(&head, "</%s>", open[j])
+	}
+
+	for _, tag := range open {
+		fmt.Fprintf(&tail, "<%s>", tag)
+	}
+
+	if i < len(s) {
+		tail.WriteString(sgr)
+	}
+
+	tail.WriteString(s[i:])
+
+	return head.String(), tail.String()
+}
+
+// truncateText shortens s to width columns, ending in an ellipsis.
+func truncateText(s string, width int) string {
+	if textWidth(s) <= width {
+		return s
+	}
+
+	head, _ := cutText(s, max(width-1, 0))
+
+	return head + "…"
+}
+
+// wrapText breaks s into lines of at most width columns, preferring to break
+// at spaces.
+func wrapText(s string, width int) []string {
+	lines := []string{}
+
+	for textWidth(s) > width {
+		plain := visibleText(s)
+		brk, w := 0, 0
+
+		for i := 0; i < len(plain); {
+			if plain[i] == ' ' {
+				brk = w
+			}
+
+			size, cw := nextCluster(plain[i:])
+
+			if w+cw > width {
+				break
+			}
+
+			w += cw
+			i += size
+		}
+
+		n := w
+
+		if brk > 0 {
+			n = brk
+		}
+
+		// a single character wider than the column still has to go somewhere
+		if n == 0 {
+			_, n = nextCluster(plain)
+		}
+
+		head, tail := cutText(s, n)
+		lines = append(lines, head)
+
+		// drop the space we broke at
+		if strings.HasPrefix(visibleText(tail), " ") {
+			_, tail = cutText(tail, 1)
+		}
+
+		s = tail
+	}
+
+	return append(lines, s)
+}
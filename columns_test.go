@@ -202,6 +202,29 @@ func TestColumnWriterWithTags(t *testing.T) {
 	}
 }
 
+func TestColumnWriterTrailingBackslash(t *testing.T) {
+	for _, color := range []bool{false, true} {
+		buf := &bytes.Buffer{}
+
+		ctx := testContext(buf, buf, nil)
+		ctx.engine.Writer.Color = color
+
+		cw := ctx.Columns()
+		cw.Append("windows", `C:\`)
+		cw.Append("share", `\\server\`)
+
+		if err := cw.Print(); err != nil {
+			t.Fatalf("Print() error = %v", err)
+		}
+
+		want := "windows  C:\\\nshare    \\\\server\\\n"
+
+		if got := stripColor(buf.String()); got != want {
+			t.Errorf("color %v: output = %q, want %q", color, got, want)
+		}
+	}
+}
+
 func TestColumnWriterWidths(t *testing.T) {
 	buf := &bytes.Buffer{}
 	writer := &Writer{
@@ -243,3 +266,160 @@ func TestColumnWriterWidths(t *testing.T) {
 		}
 	}
 }
+
+func TestColumnWriterFit(t *testing.T) {
+	rows := [][]any{
+		{"NAME", "DESCRIPTION", "STATUS"},
+		{"web", "serves the public website and api", "running"},
+	}
+
+	tests := []struct {
+		name     string
+		terminal bool
+		wide     bool
+		truncate []int
+		wrap     []int
+		want     []string
+	}{
+		{
+			name:     "truncate",
+			terminal: true,
+			truncate: []int{1},
+			want: []string{
+				"NAME  DESCRIPTION         STATUS",
+				"web   serves the public…  running",
+			},
+		},
+		{
+			name:     "wrap",
+			terminal: true,
+			wrap:     []int{1},
+			want: []string{
+				"NAME  DESCRIPTION         STATUS",
+				"web   serves the public   running",
+				"      website and api     ",
+			},
+		},
+		{
+			name:     "not marked",
+			terminal: true,
+			want: []string{
+				"NAME  DESCRIPTION                        STATUS",
+				"web   serves the public website and api  running",
+			},
+		},
+		{
+			name:     "wide",
+			terminal: true,
+			wide:     true,
+			truncate: []int{1},
+			want: []string{
+				"NAME  DESCRIPTION                        STATUS",
+				"web   serves the public website and api  running",
+			},
+		},
+		{
+			name:     "wide wrap",
+			terminal: true,
+			wide:     true,
+			wrap:     []int{1},
+			want: []string{
+				"NAME  DESCRIPTION                        STATUS",
+				"web   serves the public website and api  running",
+			},
+		},
+		{
+			name:     "not a terminal",
+			truncate: []int{1},
+			want: []string{
+				"NAME  DESCRIPTION                        STATUS",
+				"web   serves the public website and api  running",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout interface {
+				String() string
+				Write([]byte) (int, error)
+			} = &bytes.Buffer{}
+
+			if tt.terminal {
+				stdout = &testTerminal{height: 24, width: 33}
+			}
+
+			wide := BoolFlag("wide", "", "")
+			wide.Value = tt.wide
+
+			ctx := &defaultContext{
+				Context: context.Background(),
+				flags:   Flags{&wide},
+				engine: &Engine{
+					Writer: &Writer{Stdout: stdout, Tags: map[string]Renderer{}},
+				},
+			}
+
+			cw := ctx.Columns()
+			cw.Truncate(tt.truncate...)
+			cw.Wrap(tt.wrap...)
+
+			for _, row := range rows {
+				cw.Append(row...)
+			}
+
+			if err := cw.Print(); err != nil {
+				t.Fatalf("Print() error = %v", err)
+			}
+
+			got := strings.Split(strings.TrimSuffix(stripTags(stdout.String()), "\n"), "\n")
+
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("output =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"much too long", 8, "much to…"},
+		{"<id>abcdefgh</id>", 5, "<id>abcd</id>…"},
+		{"a<b>bold</b>c", 4, "a<b>bo</b>…"},
+		{"<none> value", 5, "<non…"},
+		{"héllo wörld", 6, "héllo…"},
+	}
+
+	for _, tt := range tests {
+		if got := truncateText(tt.s, tt.width); got != tt.want {
+			t.Errorf("truncateText(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  []string
+	}{
+		{"short", 10, []string{"short"}},
+		{"one two three", 7, []string{"one two", "three"}},
+		{"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"<b>one two</b> three", 5, []string{"<b>one</b>", "<b>two</b>", "three"}},
+	}
+
+	for _, tt := range tests {
+		got := wrapText(tt.s, tt.width)
+
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("wrapText(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package stdcli
 
 import (
 	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -16,8 +17,11 @@ type Command struct {
 	Flags       []Flag
 	Invisible   bool
 	Handler     HandlerFunc
+	NoPager     bool
+	Table       bool
 	Usage       string
 	Validate    Validator
+	Watch       bool
 
 	engine *Engine
 }
@@ -25,8 +29,11 @@ type Command struct {
 type CommandOptions struct {
 	Flags     []Flag
 	Invisible bool
+	NoPager   bool
+	Table     bool
 	Usage     string
 	Validate  Validator
+	Watch     bool
 }
 
 type HandlerFunc func(Context) error
@@ -39,16 +46,30 @@ func registerFlags(fs *pflag.FlagSet, flags *[]*Flag, flagDefs []Flag) {
 		if f.Kind() == FlagBool {
 			flag.NoOptDefVal = "true"
 		}
+		if f.optional != "" {
+			flag.NoOptDefVal = f.optional
+		}
 	}
 }
 
 func (c *Command) ExecuteContext(ctx context.Context, args []string) error {
+	cc, err := c.execute(ctx, args)
+
+	cc.close()
+
+	return err //nowrap
+}
+
+func (c *Command) execute(ctx context.Context, args []string) (*defaultContext, error) {
 	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
 
+	// Parse errors are reported by the engine
+	fs.SetOutput(io.Discard)
+
 	flags := []*Flag{}
 
 	// Add global flags first, then command-specific flags
-	registerFlags(fs, &flags, c.engine.Flags)
+	registerFlags(fs, &flags, c.engine.globalFlags(c))
 	registerFlags(fs, &flags, c.Flags)
 
 	// Create context before parsing so Usage function can use it
@@ -59,34 +80,74 @@ func (c *Command) ExecuteContext(ctx context.Context, args []string) error {
 		engine:  c.engine,
 	}
 
-	// Set custom usage function before parsing so --help uses our format
-	fs.Usage = func() { helpCommand(cc, c.engine, c) }
+	// Defer usage until after parsing so --help uses our format and parse
+	// errors can skip it when stdout must stay valid JSON
+	usage := false
+	fs.Usage = func() { usage = true }
 
-	if err := fs.Parse(args); err != nil {
+	err := fs.Parse(args)
+
+	if c.engine.pageable(c, cc, err == pflag.ErrHelp) {
+		stdout := c.engine.Writer.Stdout
+		p := newPager(ctx, c.engine, stdout)
+		c.engine.Writer.Stdout = p
+
+		defer func() {
+			c.engine.Writer.Stdout = stdout
+			p.Close() //nolint:errcheck
+		}()
+	}
+
+	if err != nil {
+		if usage && (err == pflag.ErrHelp || cc.Flags().String("output") != "json") {
+			helpCommand(cc, c.engine, c)
+		}
 		if strings.HasPrefix(err.Error(), "unknown shorthand flag") {
 			parts := strings.Split(err.Error(), " ")
-			return errors.Errorf("unknown flag: %s", parts[len(parts)-1])
+			return cc, errors.Errorf("unknown flag: %s", parts[len(parts)-1])
 		}
 		if err == pflag.ErrHelp {
-			return nil
+			return cc, nil
 		}
-		return errors.Wrap(err)
+		return cc, errors.Wrap(err)
 	}
 
 	// Update context with parsed args
 	cc.args = fs.Args()
 
+	// Keep the color mode until errors have been written
+	mode := cc.Flags().String("color")
+
+	if mode == "" {
+		mode = c.engine.ColorMode
+	}
+
+	restore, err := c.engine.Writer.applyColor(mode)
+	cc.onClose(restore)
+
+	if err != nil {
+		return cc, err //nowrap
+	}
+
+	if _, err := cc.logLevel(); err != nil {
+		return cc, err //nowrap
+	}
+
 	if c.Validate != nil {
 		if err := c.Validate(cc); err != nil {
-			return err //nowrap
+			return cc, err //nowrap
 		}
 	}
 
+	if interval := cc.Flags().Duration("watch"); c.Watch && interval > 0 {
+		return cc, c.watch(cc, interval)
+	}
+
 	if err := c.Handler(cc); err != nil {
-		return err //nowrap
+		return cc, err //nowrap
 	}
 
-	return nil
+	return cc, nil
 }
 
 func (c *Command) FullCommand() string {
@@ -4,10 +4,11 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"sync"
 
 	"go.ddollar.dev/errors"
-	"golang.org/x/term"
 )
 
 type Context interface {
@@ -17,27 +18,49 @@ type Context interface {
 	Arg(i int) string
 	Args() []string
 	Cleanup(func())
+	Diff(oldName, old, newName, new string) DiffWriter
 	Execute(cmd string, args ...string) ([]byte, error)
 	Flags() Flags
 	Info() InfoWriter
 	IsTerminal() bool
 	IsTerminalReader() bool
 	IsTerminalWriter() bool
+	Logger() *slog.Logger
+	Multiplex(names ...string) Multiplexer
+	MultiSelect(label string, options []string, defs []int) ([]int, error)
+	Progress(total int) ProgressBar
+	Prompt(label string, opts PromptOptions) (string, error)
+	PromptSecret(label string, opts SecretOptions) (string, error)
 	ReadSecret() (string, error)
 	Run(cmd string, args ...string) error
+	RunWriter(w io.Writer, cmd string, args ...string) error
+	Select(label string, options []string, def int) (int, error)
+	Spinner(label string) Spinner
+	Stderr() io.Writer
+	Step(label string, fn func() error) error
 	Table(columns ...any) TableWriter
+	TableStream(columns ...any) TableStream
 	Columns() ColumnWriter
+	Confirm(label string, def bool) (bool, error)
 	Terminal(cmd string, args ...string) error
+	Tree() TreeWriter
 	Version() string
+	Warnf(format string, args ...any)
 	Writef(format string, args ...any)
 }
 
 type defaultContext struct {
 	context.Context
 
-	args   []string
-	engine *Engine
-	flags  Flags
+	args     []string
+	closers  []func()
+	engine   *Engine
+	flags    Flags
+	logger   *slog.Logger
+	mu       sync.Mutex
+	once     sync.Once
+	steps    []*step
+	warnings []string
 }
 
 var _ Context = &defaultContext{}
@@ -98,8 +121,30 @@ func (c *defaultContext) IsTerminalWriter() bool {
 	return c.engine.Writer.IsTerminal()
 }
 
+// Logger returns a logger that writes to stderr at the level chosen by
+// --log-level, --debug, LOG_LEVEL or DEBUG=true. If the engine has a LogFile
+// all records are also written to it as JSON.
+func (c *defaultContext) Logger() *slog.Logger {
+	c.once.Do(func() {
+		level, _ := c.logLevel()
+
+		var h slog.Handler = newTagHandler(c.engine.Writer, level)
+
+		if c.engine.LogFile != "" {
+			if fd, err := os.OpenFile(c.engine.LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600); err == nil {
+				c.onClose(func() { fd.Close() })
+				h = multiHandler{h, slog.NewJSONHandler(fd, &slog.HandlerOptions{Level: slog.LevelDebug})}
+			}
+		}
+
+		c.logger = slog.New(h)
+	})
+
+	return c.logger
+}
+
 func (c *defaultContext) Read(data []byte) (int, error) {
-	n, err := c.engine.Reader.Read(data)
+	n, err := c.reader().Read(data)
 	if err != nil {
 		return 0, errors.Wrap(err)
 	}
@@ -108,26 +153,29 @@ func (c *defaultContext) Read(data []byte) (int, error) {
 }
 
 func (c *defaultContext) ReadSecret() (string, error) {
-	data, err := term.ReadPassword(int(os.Stdin.Fd()))
-	if err != nil {
-		return "", errors.Wrap(err)
-	}
-
-	return string(data), nil
+	return c.PromptSecret("", SecretOptions{})
 }
 
 func (c *defaultContext) Run(cmd string, args ...string) error {
+	return c.RunWriter(c, cmd, args...)
+}
+
+// RunWriter runs a command with its output written to w, such as a writer
+// from Multiplex.
+func (c *defaultContext) RunWriter(w io.Writer, cmd string, args ...string) error {
 	if c.engine.Executor == nil {
 		return errors.Errorf("no executor")
 	}
 
-	if err := c.engine.Executor.Run(c, c, cmd, args...); err != nil {
+	if err := c.engine.Executor.Run(c, w, cmd, args...); err != nil {
 		return errors.Wrap(err)
 	}
 
 	return nil
 }
 
+// Table returns a writer for a table with the given columns, each either a
+// header string or a Column.
 func (c *defaultContext) Table(columns ...any) TableWriter {
 	return &tableWriter{ctx: c, columns: columns}
 }
@@ -141,6 +189,8 @@ func (c *defaultContext) Terminal(cmd string, args ...string) error {
 		return errors.Errorf("no executor")
 	}
 
+	c.unpage()
+
 	if err := c.engine.Executor.Terminal(c, cmd, args...); err != nil {
 		return errors.Wrap(err)
 	}
@@ -159,3 +209,60 @@ func (c *defaultContext) Write(data []byte) (int, error) {
 func (c *defaultContext) Writef(format string, args ...any) {
 	c.engine.Writer.Write([]byte(fmt.Sprintf(format, args...))) //nolint:errcheck
 }
+
+// outputWidth returns the terminal width that columns should fit in, or 0 if
+// output should not be truncated.
+func (c *defaultContext) outputWidth() int {
+	if c.flags.Bool("wide") || !c.IsTerminalWriter() {
+		return 0
+	}
+
+	width, _, ok := terminalSize(c.engine.Writer.Stdout)
+	if !ok {
+		return 0
+	}
+
+	return width
+}
+
+func (c *defaultContext) close() {
+	c.mu.Lock()
+	closers := c.closers
+	c.closers = nil
+	c.mu.Unlock()
+
+	for i := len(closers) - 1; i >= 0; i-- {
+		closers[i]()
+	}
+}
+
+// fork returns a context with the same arguments and flags as c but its own
+// cleanups, steps and warnings.
+func (c *defaultContext) fork() *defaultContext {
+	return &defaultContext{
+		Context: c.Context,
+		args:    c.args,
+		engine:  c.engine,
+		flags:   c.flags,
+	}
+}
+
+func (c *defaultContext) logLevel() (slog.Level, error) {
+	if l := c.flags.String("log-level"); l != "" {
+		return parseLogLevel(l)
+	}
+
+	if c.flags.Bool("debug") {
+		return slog.LevelDebug, nil
+	}
+
+	return envLogLevel(), nil
+}
+
+// onClose registers fn to run when the command handler has returned.
+func (c *defaultContext) onClose(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closers = append(c.closers, fn)
+}
@@ -3,10 +3,31 @@ package stdcli
 import (
 	"bytes"
 	"context"
+	"io"
 	"strings"
 	"testing"
 )
 
+// testContext returns a context that writes to stdout and stderr with a
+// string flag set for each entry in flags.
+func testContext(stdout, stderr io.Writer, flags map[string]string) *defaultContext {
+	fs := Flags{}
+
+	for name, value := range flags {
+		f := StringFlag(name, "", "")
+		f.Value = value
+		fs = append(fs, &f)
+	}
+
+	return &defaultContext{
+		Context: context.Background(),
+		flags:   fs,
+		engine: &Engine{
+			Writer: &Writer{Stdout: stdout, Stderr: stderr, Tags: DefaultWriter.Tags},
+		},
+	}
+}
+
 func TestContextArg(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -0,0 +1,517 @@
+package stdcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type DiffWriter interface {
+	ContextLines(n int)
+	Print() error
+	SideBySide()
+}
+
+const (
+	defaultDiffContext = 3
+
+	// sideBySideMinWidth is the narrowest each side of a side-by-side diff
+	// can be before falling back to a unified diff.
+	sideBySideMinWidth = 40
+)
+
+type diffOp byte
+
+const (
+	diffContext diffOp = ' '
+	diffDelete  diffOp = '-'
+	diffAdd     diffOp = '+'
+)
+
+type diffLine struct {
+	op   diffOp
+	text string
+}
+
+type diffHunk struct {
+	lines    []diffLine
+	newLines int
+	newStart int
+	oldLines int
+	oldStart int
+}
+
+type diffWriter struct {
+	context    int
+	ctx        *defaultContext
+	newName    string
+	newText    string
+	oldName    string
+	oldText    string
+	sideBySide bool
+}
+
+var _ DiffWriter = &diffWriter{}
+
+// Diff returns a writer for a line diff between old and new, shown as a
+// unified diff or side by side.
+func (c *defaultContext) Diff(oldName, old, newName, new string) DiffWriter {
+	return &diffWriter{context: defaultDiffContext, ctx: c, newName: newName, newText: new, oldName: oldName, oldText: old}
+}
+
+// ContextLines sets how many unchanged lines are shown around each change.
+func (d *diffWriter) ContextLines(n int) {
+	d.context = max(n, 0)
+}
+
+// SideBySide shows old and new next to each other when the terminal is wide
+// enough.
+func (d *diffWriter) SideBySide() {
+	d.sideBySide = true
+}
+
+func (d *diffWriter) Print() error {
+	hunks := diffHunks(diffLines(splitLines(d.oldText), splitLines(d.newText)), d.context)
+
+	if d.ctx.Flags().String("output") == "json" {
+		return d.printJSON(hunks)
+	}
+
+	if width := d.ctx.outputWidth(); d.sideBySide && (width-3)/2 >= sideBySideMinWidth {
+		d.printSideBySide(hunks, (width-3)/2)
+		return nil
+	}
+
+	d.printUnified(hunks)
+
+	return nil
+}
+
+type diffJSON struct {
+	Hunks []diffHunkJSON `json:"hunks"`
+	New   string         `json:"new"`
+	Old   string         `json:"old"`
+}
+
+type diffHunkJSON struct {
+	Lines    []diffLineJSON `json:"lines"`
+	NewLines int            `json:"new_lines"`
+	NewStart int            `json:"new_start"`
+	OldLines int            `json:"old_lines"`
+	OldStart int            `json:"old_start"`
+}
+
+type diffLineJSON struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+func (d *diffWriter) printJSON(hunks []diffHunk) error {
+	v := diffJSON{Hunks: []diffHunkJSON{}, New: d.newName, Old: d.oldName}
+
+	for _, h := range hunks {
+		hj := diffHunkJSON{NewLines: h.newLines, NewStart: h.newStart, OldLines: h.oldLines, OldStart: h.oldStart}
+
+		for _, l := range h.lines {
+			hj.Lines = append(hj.Lines, diffLineJSON{Op: l.op.String(), Text: l.text})
+		}
+
+		v.Hunks = append(v.Hunks, hj)
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err //nowrap
+	}
+
+	if _, err := d.ctx.Write(data); err != nil {
+		return err //nowrap
+	}
+
+	return nil
+}
+
+func (d *diffWriter) printUnified(hunks []diffHunk) {
+	if len(hunks) == 0 {
+		return
+	}
+
+	d.ctx.Writef("%s\n", diffDelete.render("--- "+EscapeTags(d.oldName)))
+	d.ctx.Writef("%s\n", diffAdd.render("+++ "+EscapeTags(d.newName)))
+
+	for _, h := range hunks {
+		d.ctx.Writef("<diff-hunk>%s</diff-hunk>\n", h.header())
+
+		for _, l := range h.lines {
+			d.ctx.Writef("%s\n", l.op.render(string(l.op)+EscapeTags(l.text)))
+		}
+	}
+}
+
+func (d *diffWriter) printSideBySide(hunks []diffHunk, width int) {
+	if len(hunks) == 0 {
+		return
+	}
+
+	d.ctx.Writef("%s   %s\n", sideBySideCell(diffDelete, EscapeTags(d.oldName), width), diffAdd.render(truncateText(EscapeTags(d.newName), width)))
+
+	for _, h := range hunks {
+		d.ctx.Writef("<diff-hunk>%s</diff-hunk>\n", h.header())
+
+		for i := 0; i < len(h.lines); {
+			if h.lines[i].op == diffContext {
+				text := EscapeTags(h.lines[i].text)
+				d.ctx.Writef("%s   %s\n", sideBySideCell(diffContext, text, width), truncateText(text, width))
+				i++
+				continue
+			}
+
+			// pair a run of deletions with the additions that follow it
+			dels, adds := []string{}, []string{}
+
+			for ; i < len(h.lines) && h.lines[i].op == diffDelete; i++ {
+				dels = append(dels, EscapeTags(h.lines[i].text))
+			}
+
+			for ; i < len(h.lines) && h.lines[i].op == diffAdd; i++ {
+				adds = append(adds, EscapeTags(h.lines[i].text))
+			}
+
+			for j := 0; j < max(len(dels), len(adds)); j++ {
+				left, right, marker := "", "", "|"
+
+				switch {
+				case j >= len(adds):
+					left, marker = sideBySideCell(diffDelete, dels[j], width), "<"
+				case j >= len(dels):
+					left, right, marker = strings.Repeat(" ", width), diffAdd.render(truncateText(adds[j], width)), ">"
+				default:
+					left, right = sideBySideCell(diffDelete, dels[j], width), diffAdd.render(truncateText(adds[j], width))
+				}
+
+				d.ctx.Writef("%s %s %s\n", left, marker, right)
+			}
+		}
+	}
+}
+
+// sideBySideCell fits text to the left column of a side-by-side diff.
+func sideBySideCell(op diffOp, text string, width int) string {
+	text = truncateText(text, width)
+
+	return op.render(text) + strings.Repeat(" ", width-textWidth(text))
+}
+
+func (h diffHunk) header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", diffRange(h.oldStart, h.oldLines), diffRange(h.newStart, h.newLines))
+}
+
+func diffRange(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+func (op diffOp) render(s string) string {
+	switch op {
+	case diffAdd:
+		return wrapTag("diff-add", s)
+	case diffDelete:
+		return wrapTag("diff-delete", s)
+	default:
+		return s
+	}
+}
+
+func (op diffOp) String() string {
+	switch op {
+	case diffAdd:
+		return "add"
+	case diffDelete:
+		return "delete"
+	default:
+		return "context"
+	}
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines returns the shortest edit script from a to b using the linear
+// space variant of the Myers algorithm.
+func diffLines(a, b []string) []diffLine {
+	ids := map[string]int{}
+	ai, bi := lineIDs(a, ids), lineIDs(b, ids)
+
+	// lines only one side has can never match, so they are left out of the
+	// search and added back as edits afterwards
+	ka, kb := sharedLines(ai, bi), sharedLines(bi, ai)
+
+	d := &differ{a: pick(ai, ka), b: pick(bi, kb)}
+	d.diff(0, len(d.a), 0, len(d.b))
+
+	lines := []diffLine{}
+	i, j, pa, pb := 0, 0, 0, 0
+
+	deleteTo := func(n int) {
+		for ; i < n; i++ {
+			lines = append(lines, diffLine{op: diffDelete, text: a[i]})
+		}
+	}
+
+	addTo := func(n int) {
+		for ; j < n; j++ {
+			lines = append(lines, diffLine{op: diffAdd, text: b[j]})
+		}
+	}
+
+	for _, op := range d.ops {
+		switch op {
+		case diffContext:
+			deleteTo(ka[pa])
+			addTo(kb[pb])
+			lines = append(lines, diffLine{op: diffContext, text: a[i]})
+			i, j, pa, pb = i+1, j+1, pa+1, pb+1
+		case diffDelete:
+			deleteTo(ka[pa] + 1)
+			pa++
+		case diffAdd:
+			addTo(kb[pb] + 1)
+			pb++
+		}
+	}
+
+	deleteTo(len(a))
+	addTo(len(b))
+
+	return lines
+}
+
+// differ finds the edits between two sequences of line ids.
+type differ struct {
+	a   []int
+	b   []int
+	ops []diffOp
+}
+
+func lineIDs(lines []string, ids map[string]int) []int {
+	out := make([]int, len(lines))
+
+	for i, l := range lines {
+		id, ok := ids[l]
+		if !ok {
+			id = len(ids)
+			ids[l] = id
+		}
+		out[i] = id
+	}
+
+	return out
+}
+
+// sharedLines returns the indexes of the lines in a that also appear in b.
+func sharedLines(a, b []int) []int {
+	in := map[int]bool{}
+
+	for _, id := range b {
+		in[id] = true
+	}
+
+	shared := []int{}
+
+	for i, id := range a {
+		if in[id] {
+			shared = append(shared, i)
+		}
+	}
+
+	return shared
+}
+
+func pick(ids, indexes []int) []int {
+	out := make([]int, len(indexes))
+
+	for i, j := range indexes {
+		out[i] = ids[j]
+	}
+
+	return out
+}
+
+func (d *differ) add(op diffOp, n int) {
+	for i := 0; i < n; i++ {
+		d.ops = append(d.ops, op)
+	}
+}
+
+// diff appends the edits from a[aLo:aHi] to b[bLo:bHi], splitting the
+// problem at the middle snake of an optimal path.
+func (d *differ) diff(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.ops = append(d.ops, diffContext)
+		aLo++
+		bLo++
+	}
+
+	suffix := aHi
+
+	for aHi > aLo && bHi > bLo && d.a[aHi-1] == d.b[bHi-1] {
+		aHi--
+		bHi--
+	}
+
+	switch {
+	case aLo == aHi:
+		d.add(diffAdd, bHi-bLo)
+	case bLo == bHi:
+		d.add(diffDelete, aHi-aLo)
+	default:
+		// with the common ends removed at least two edits remain, so both
+		// halves are smaller than the whole
+		x, y, u, v := d.middleSnake(aLo, aHi, bLo, bHi)
+
+		d.diff(aLo, x, bLo, y)
+		d.add(diffContext, u-x)
+		d.diff(u, aHi, v, bHi)
+	}
+
+	d.add(diffContext, suffix-aHi)
+}
+
+// middleSnake searches forward from the start and backward from the end at
+// the same time and returns the run of matching lines from (x, y) to (u, v)
+// where the two searches meet.
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (int, int, int, int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+	limit := (n + m + 1) / 2
+	offset := limit + 1
+
+	// furthest x on each diagonal, counted from the end for the backward
+	// search, whose diagonal k matches the forward diagonal delta-k
+	forward := make([]int, 2*limit+3)
+	backward := make([]int, 2*limit+3)
+
+	for e := 0; e <= limit; e++ {
+		for k := -e; k <= e; k += 2 {
+			x := furthest(forward, offset, k, e)
+			y := x - k
+			x0, y0 := x, y
+
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+
+			forward[offset+k] = x
+
+			if odd && k >= delta-(e-1) && k <= delta+(e-1) && x+backward[offset+delta-k] >= n {
+				return aLo + x0, bLo + y0, aLo + x, bLo + y
+			}
+		}
+
+		for k := -e; k <= e; k += 2 {
+			x := furthest(backward, offset, k, e)
+			y := x - k
+			x0, y0 := x, y
+
+			for x < n && y < m && d.a[aHi-1-x] == d.b[bHi-1-y] {
+				x++
+				y++
+			}
+
+			backward[offset+k] = x
+
+			if !odd && k >= delta-e && k <= delta+e && x+forward[offset+delta-k] >= n {
+				return aHi - x, bHi - y, aHi - x0, bHi - y0
+			}
+		}
+	}
+
+	// the searches always meet within limit edits
+	return aLo, bLo, aLo, bLo
+}
+
+// furthest returns where the path on diagonal k starts after e edits, moving
+// down from k+1 or right from k-1, whichever got further.
+func furthest(v []int, offset, k, e int) int {
+	if k == -e || (k != e && v[offset+k-1] < v[offset+k+1]) {
+		return v[offset+k+1]
+	}
+
+	return v[offset+k-1] + 1
+}
+
+// diffHunks groups changed lines with up to context unchanged lines around
+// them, merging groups that overlap.
+func diffHunks(lines []diffLine, context int) []diffHunk {
+	hunks := []diffHunk{}
+
+	for i := 0; i < len(lines); {
+		if lines[i].op == diffContext {
+			i++
+			continue
+		}
+
+		start := max(i-context, 0)
+		end := i
+
+		// extend while the next change is close enough to share context
+		for j := i; j < len(lines); j++ {
+			if lines[j].op != diffContext {
+				end = j + 1
+			} else if j-end >= 2*context {
+				break
+			}
+		}
+
+		end = min(end+context, len(lines))
+
+		hunks = append(hunks, newDiffHunk(lines, start, end))
+
+		i = end
+	}
+
+	return hunks
+}
+
+func newDiffHunk(lines []diffLine, start, end int) diffHunk {
+	h := diffHunk{lines: lines[start:end], oldStart: 1, newStart: 1}
+
+	for _, l := range lines[:start] {
+		if l.op != diffAdd {
+			h.oldStart++
+		}
+		if l.op != diffDelete {
+			h.newStart++
+		}
+	}
+
+	for _, l := range h.lines {
+		if l.op != diffAdd {
+			h.oldLines++
+		}
+		if l.op != diffDelete {
+			h.newLines++
+		}
+	}
+
+	// an empty range starts at the line before it
+	if h.oldLines == 0 {
+		h.oldStart--
+	}
+
+	if h.newLines == 0 {
+		h.newStart--
+	}
+
+	return h
+}
@@ -0,0 +1,406 @@
+package stdcli
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want string
+	}{
+		{name: "equal", a: "a\nb\n", b: "a\nb\n", want: " a b"},
+		{name: "empty", a: "", b: "", want: ""},
+		{name: "added", a: "", b: "a\nb\n", want: "+a+b"},
+		{name: "deleted", a: "a\nb\n", b: "", want: "-a-b"},
+		{name: "changed", a: "a\nb\nc\n", b: "a\nx\nc\n", want: " a-b+x c"},
+		{name: "moved", a: "a\nb\nc\n", b: "b\nc\na\n", want: "-a b c+a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+
+			for _, l := range diffLines(splitLines(tt.a), splitLines(tt.b)) {
+				got += string(l.op) + l.text
+			}
+
+			if got != tt.want {
+				t.Errorf("diffLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func numberedLines(n int, change map[int]string) string {
+	lines := []string{}
+
+	for i := 1; i <= n; i++ {
+		if s, ok := change[i]; ok {
+			lines = append(lines, s)
+		} else {
+			lines = append(lines, strings.Repeat("x", i))
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func TestDiffWriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		context int
+		want    []string
+	}{
+		{
+			name:    "no changes",
+			old:     "a\nb\n",
+			new:     "a\nb\n",
+			context: 3,
+			want:    []string{},
+		},
+		{
+			name:    "single hunk",
+			old:     "a\nb\nc\n",
+			new:     "a\nx\nc\nd\n",
+			context: 3,
+			want: []string{
+				"--- old",
+				"+++ new",
+				"@@ -1,3 +1,4 @@",
+				" a",
+				"-b",
+				"+x",
+				" c",
+				"+d",
+			},
+		},
+		{
+			name:    "separate hunks",
+			old:     numberedLines(10, nil),
+			new:     numberedLines(10, map[int]string{2: "two", 9: "nine"}),
+			context: 1,
+			want: []string{
+				"--- old",
+				"+++ new",
+				"@@ -1,3 +1,3 @@",
+				" x",
+				"-xx",
+				"+two",
+				" xxx",
+				"@@ -8,3 +8,3 @@",
+				" xxxxxxxx",
+				"-xxxxxxxxx",
+				"+nine",
+				" xxxxxxxxxx",
+			},
+		},
+		{
+			name:    "merged hunks",
+			old:     numberedLines(10, nil),
+			new:     numberedLines(10, map[int]string{2: "two", 5: "five"}),
+			context: 1,
+			want: []string{
+				"--- old",
+				"+++ new",
+				"@@ -1,6 +1,6 @@",
+				" x",
+				"-xx",
+				"+two",
+				" xxx",
+				" xxxx",
+				"-xxxxx",
+				"+five",
+				" xxxxxx",
+			},
+		},
+		{
+			name:    "created",
+			old:     "",
+			new:     "a\n",
+			context: 3,
+			want: []string{
+				"--- old",
+				"+++ new",
+				"@@ -0,0 +1 @@",
+				"+a",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+
+			dw := testContext(buf, buf, nil).Diff("old", tt.old, "new", tt.new)
+			dw.ContextLines(tt.context)
+
+			if err := dw.Print(); err != nil {
+				t.Fatalf("Print() error = %v", err)
+			}
+
+			want := ""
+			if len(tt.want) > 0 {
+				want = strings.Join(tt.want, "\n") + "\n"
+			}
+
+			if got := stripColor(buf.String()); got != want {
+				t.Errorf("output =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestDiffWriterTrailingBackslash(t *testing.T) {
+	old := "RUN apt-get update && \\\n  apt-get install -y curl\n"
+	new := "RUN apt-get update && \\\n  apt-get install -y git\n"
+
+	want := strings.Join([]string{
+		"--- old\\",
+		"+++ new\\",
+		"@@ -1,2 +1,2 @@",
+		" RUN apt-get update && \\",
+		"-  apt-get install -y curl",
+		"+  apt-get install -y git",
+	}, "\n") + "\n"
+
+	for _, color := range []bool{false, true} {
+		buf := &bytes.Buffer{}
+
+		ctx := testContext(buf, buf, nil)
+		ctx.engine.Writer.Color = color
+
+		if err := ctx.Diff(`old\`, old, `new\`, new).Print(); err != nil {
+			t.Fatalf("Print() error = %v", err)
+		}
+
+		if got := stripColor(buf.String()); got != want {
+			t.Errorf("color %v: output =\n%s\nwant\n%s", color, got, want)
+		}
+	}
+}
+
+func TestDiffWriterSideBySide(t *testing.T) {
+	tests := []struct {
+		name  string
+		width int
+		want  []string
+	}{
+		{
+			name:  "wide",
+			width: 89,
+			want: []string{
+				"old" + strings.Repeat(" ", 40) + "   new",
+				"@@ -1,4 +1,4 @@",
+				"a" + strings.Repeat(" ", 42) + " | x",
+				"b" + strings.Repeat(" ", 42) + "   b",
+				"c" + strings.Repeat(" ", 42) + " < ",
+				"d" + strings.Repeat(" ", 42) + "   d",
+				strings.Repeat(" ", 43) + " > e",
+			},
+		},
+		{
+			name:  "narrow",
+			width: 80,
+			want: []string{
+				"--- old",
+				"+++ new",
+				"@@ -1,4 +1,4 @@",
+				"-a",
+				"+x",
+				" b",
+				"-c",
+				" d",
+				"+e",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &testTerminal{height: 24, width: tt.width}
+
+			dw := testContext(stdout, stdout, nil).Diff("old", "a\nb\nc\nd\n", "new", "x\nb\nd\ne\n")
+			dw.SideBySide()
+
+			if err := dw.Print(); err != nil {
+				t.Fatalf("Print() error = %v", err)
+			}
+
+			if got, want := stripColor(stdout.String()), strings.Join(tt.want, "\n")+"\n"; got != want {
+				t.Errorf("output =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestDiffWriterJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	if err := testContext(buf, buf, map[string]string{"output": "json"}).Diff("old", "a\nb\n", "new", "a\nc\n").Print(); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+
+	want := `{
+  "hunks": [
+    {
+      "lines": [
+        {
+          "op": "context",
+          "text": "a"
+        },
+        {
+          "op": "delete",
+          "text": "b"
+        },
+        {
+          "op": "add",
+          "text": "c"
+        }
+      ],
+      "new_lines": 2,
+      "new_start": 1,
+      "old_lines": 2,
+      "old_start": 1
+    }
+  ],
+  "new": "new",
+  "old": "old"
+}`
+
+	if got := buf.String(); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// editDistance is the number of insertions and deletions in the shortest
+// edit script, computed with the quadratic LCS table.
+func editDistance(a, b []string) int {
+	lcs := make([][]int, len(a)+1)
+
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	return len(a) + len(b) - 2*lcs[0][0]
+}
+
+// checkDiff verifies that lines turns a into b with the fewest edits.
+func checkDiff(t *testing.T, a, b []string, lines []diffLine, minimal bool) {
+	t.Helper()
+
+	old, new, edits := []string{}, []string{}, 0
+
+	for _, l := range lines {
+		if l.op != diffAdd {
+			old = append(old, l.text)
+		}
+		if l.op != diffDelete {
+			new = append(new, l.text)
+		}
+		if l.op != diffContext {
+			edits++
+		}
+	}
+
+	if strings.Join(old, "\n") != strings.Join(a, "\n") || strings.Join(new, "\n") != strings.Join(b, "\n") {
+		t.Fatalf("diff does not turn a into b")
+	}
+
+	if !minimal {
+		return
+	}
+
+	if want := editDistance(a, b); edits != want {
+		t.Errorf("diff has %d edits, want %d", edits, want)
+	}
+}
+
+func TestDiffLinesMinimal(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	random := func() []string {
+		lines := make([]string, rnd.Intn(30))
+
+		for i := range lines {
+			lines[i] = string(rune('a' + rnd.Intn(4)))
+		}
+
+		return lines
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := random(), random()
+		checkDiff(t, a, b, diffLines(a, b), true)
+	}
+}
+
+func TestDiffLinesLarge(t *testing.T) {
+	tests := []struct {
+		name string
+		a    func(i int) string
+		b    func(i int) string
+	}{
+		{
+			name: "different",
+			a:    func(i int) string { return fmt.Sprintf("old %d", i) },
+			b:    func(i int) string { return fmt.Sprintf("new %d", i) },
+		},
+		{
+			name: "reordered",
+			a:    func(i int) string { return fmt.Sprintf("line %d", i%100) },
+			b:    func(i int) string { return fmt.Sprintf("line %d", i*37%100) },
+		},
+		{
+			name: "scattered changes",
+			a:    func(i int) string { return fmt.Sprintf("line %d", i) },
+			b: func(i int) string {
+				if i%100 == 0 {
+					return fmt.Sprintf("changed %d", i)
+				}
+				return fmt.Sprintf("line %d", i)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := make([]string, 10000), make([]string, 10000)
+
+			for i := range a {
+				a[i], b[i] = tt.a(i), tt.b(i)
+			}
+
+			var before, after runtime.MemStats
+
+			runtime.ReadMemStats(&before)
+			lines := diffLines(a, b)
+			runtime.ReadMemStats(&after)
+
+			if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 64<<20 {
+				t.Errorf("diffLines() allocated %d MB", alloc>>20)
+			}
+
+			checkDiff(t, a, b, lines, false)
+		})
+	}
+}
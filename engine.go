@@ -5,20 +5,52 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
+	"time"
 
 	"go.ddollar.dev/errors"
 )
 
 type Engine struct {
-	Commands []Command
-	Executor Executor
-	Flags    []Flag
-	Name     string
-	Reader   *Reader
-	Settings string
-	Version  string
-	Writer   *Writer
+	// ColorMode is auto, always or never for commands run without --color,
+	// defaulting to auto which lets NO_COLOR and FORCE_COLOR decide
+	ColorMode string
+
+	Commands  []Command
+	Executor  Executor
+	Flags     []Flag
+	Formats   map[string]Formatter
+	LogFile   string
+	Name      string
+	NoPager   bool
+	Reader    *Reader
+	Settings  string
+	Telemetry Telemetry
+	Version   string
+	Writer    *Writer
+
+	// WarningSummary lists warnings once a command finishes instead of
+	// writing each one as it happens
+	WarningSummary bool
+}
+
+// builtinFlags are added to every command unless the app already defines a
+// flag with the same name.
+var builtinFlags = []Flag{
+	StringFlag("color", "", "use color: auto, always or never"),
+	BoolFlag("debug", "", "enable debug logging"),
+	StringFlag("format", "", "format output using a go template"),
+	StringFlag("log-level", "", "log level: debug, info, warn or error"),
+	BoolFlag("no-pager", "", "do not pipe output through a pager"),
+	BoolFlag("wide", "", "do not truncate output to the terminal width"),
+}
+
+// tableFlags are added to commands that set CommandOptions.Table.
+var tableFlags = []Flag{
+	StringFlag("columns", "", "comma separated columns to show"),
+	StringFlag("filter", "", "only show rows matching key=value or key~value"),
+	StringFlag("sort", "", "sort by comma separated columns, prefix with - to reverse"),
 }
 
 func (e *Engine) Command(command, description string, fn HandlerFunc, opts CommandOptions) {
@@ -28,8 +60,11 @@ func (e *Engine) Command(command, description string, fn HandlerFunc, opts Comma
 		Handler:     fn,
 		Flags:       opts.Flags,
 		Invisible:   opts.Invisible,
+		NoPager:     opts.NoPager,
+		Table:       opts.Table,
 		Usage:       opts.Usage,
 		Validate:    opts.Validate,
+		Watch:       opts.Watch,
 		engine:      e,
 	})
 }
@@ -64,14 +99,88 @@ func (e *Engine) ExecuteContext(ctx context.Context, args []string) int {
 		m = &(e.Commands[0])
 	}
 
-	err := m.ExecuteContext(ctx, cargs)
+	start := time.Now()
+
+	cc, err := m.execute(ctx, cargs)
+
+	code := e.handleError(cc, err)
+
+	e.writeWarnings(cc, err)
+
+	e.record(ctx, m, cc, start, code, err)
+
+	cc.close()
+
+	return code
+}
+
+func (e *Engine) handleError(ctx *defaultContext, err error) int {
+	if writesError(err) {
+		e.writeError(ctx, err)
+	}
+
 	switch t := errors.Cause(err).(type) {
 	case nil:
 		return 0
 	case ExitCoder:
 		return t.ExitCode()
 	default:
-		e.Writer.Error(err) //nolint:errcheck
 		return 1
 	}
 }
+
+// writesError returns true for errors that are reported to the user rather
+// than only setting the exit code.
+func writesError(err error) bool {
+	switch errors.Cause(err).(type) {
+	case nil:
+		return false
+	case *Error:
+		return true
+	case ExitCoder:
+		return false
+	default:
+		return true
+	}
+}
+
+func (e *Engine) writeError(ctx *defaultContext, err error) {
+	if ctx.Flags().String("output") == "json" {
+		e.Writer.errorJSON(err, ctx.collectedWarnings()) //nolint:errcheck
+		return
+	}
+
+	e.Writer.error(err, ctx.Logger()) //nolint:errcheck
+}
+
+func (e *Engine) globalFlags(c *Command) []Flag {
+	flags := append([]Flag{}, e.Flags...)
+
+	builtin := slices.Clone(builtinFlags)
+
+	if c.Table {
+		builtin = append(builtin, tableFlags...)
+	}
+
+	if c.Watch {
+		builtin = append(builtin, watchFlag)
+	}
+
+	for _, f := range builtin {
+		if !hasFlag(flags, f.Name) && !hasFlag(c.Flags, f.Name) {
+			flags = append(flags, f)
+		}
+	}
+
+	return flags
+}
+
+func hasFlag(flags []Flag, name string) bool {
+	for _, f := range flags {
+		if f.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,9 +3,26 @@ package stdcli
 import (
 	"bytes"
 	"context"
+	"io"
 	"testing"
 )
 
+// testEngine returns an engine that writes to stdout and stderr, with a help
+// command to fall back to, for tests to add their own commands to.
+func testEngine(stdout, stderr io.Writer) *Engine {
+	e := &Engine{
+		Name:    "testapp",
+		Version: "1.0.0",
+		Writer:  &Writer{Stdout: stdout, Stderr: stderr, Tags: DefaultWriter.Tags},
+	}
+
+	e.Command("help", "show help", func(ctx Context) error {
+		return nil
+	}, CommandOptions{})
+
+	return e
+}
+
 func TestEngineNew(t *testing.T) {
 	e := New("testapp", "1.0.0")
 
@@ -0,0 +1,79 @@
+package stdcli
+
+import (
+	"fmt"
+
+	"go.ddollar.dev/errors"
+)
+
+// Error is a user-facing error that can carry hints, a documentation link,
+// a stable code for tooling and the exit code to use when it ends a command.
+type Error struct {
+	Code    string
+	Exit    int
+	Hints   []string
+	Message string
+	URL     string
+}
+
+var _ ExitCoder = &Error{}
+
+type errorJSON struct {
+	Code     string   `json:"code"`
+	ExitCode int      `json:"exit_code"`
+	Hints    []string `json:"hints"`
+	Message  string   `json:"message"`
+	URL      string   `json:"url,omitempty"`
+}
+
+func Errorf(format string, args ...any) *Error {
+	return &Error{Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) ExitCode() int {
+	if e.Exit == 0 {
+		return 1
+	}
+
+	return e.Exit
+}
+
+func (e *Error) WithCode(code string) *Error {
+	e.Code = code
+	return e
+}
+
+func (e *Error) WithExit(code int) *Error {
+	e.Exit = code
+	return e
+}
+
+func (e *Error) WithHint(format string, args ...any) *Error {
+	e.Hints = append(e.Hints, fmt.Sprintf(format, args...))
+	return e
+}
+
+func (e *Error) WithURL(url string) *Error {
+	e.URL = url
+	return e
+}
+
+func newErrorJSON(err error) errorJSON {
+	ej := errorJSON{ExitCode: 1, Hints: []string{}, Message: err.Error()}
+
+	if e, ok := errors.Cause(err).(*Error); ok {
+		ej.Code = e.Code
+		ej.ExitCode = e.ExitCode()
+		ej.URL = e.URL
+
+		if len(e.Hints) > 0 {
+			ej.Hints = e.Hints
+		}
+	}
+
+	return ej
+}
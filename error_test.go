@@ -0,0 +1,202 @@
+package stdcli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.ddollar.dev/errors"
+)
+
+func TestErrorf(t *testing.T) {
+	err := Errorf("app %s not found", "myapp")
+
+	if err.Error() != "app myapp not found" {
+		t.Errorf("Error() = %q, want %q", err.Error(), "app myapp not found")
+	}
+
+	if err.ExitCode() != 1 {
+		t.Errorf("ExitCode() = %d, want 1", err.ExitCode())
+	}
+}
+
+func TestErrorBuilders(t *testing.T) {
+	err := Errorf("app not found").
+		WithCode("app_not_found").
+		WithExit(3).
+		WithHint("run %q to list apps", "apps").
+		WithHint("check your spelling").
+		WithURL("https://example.org/docs/apps")
+
+	if err.Code != "app_not_found" {
+		t.Errorf("Code = %q, want %q", err.Code, "app_not_found")
+	}
+
+	if err.ExitCode() != 3 {
+		t.Errorf("ExitCode() = %d, want 3", err.ExitCode())
+	}
+
+	if len(err.Hints) != 2 || err.Hints[0] != `run "apps" to list apps` {
+		t.Errorf("Hints = %v", err.Hints)
+	}
+
+	if err.URL != "https://example.org/docs/apps" {
+		t.Errorf("URL = %q", err.URL)
+	}
+}
+
+func TestWriterErrorHints(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := &Writer{
+		Stdout: buf,
+		Stderr: buf,
+		Color:  false,
+		Tags:   DefaultWriter.Tags,
+	}
+
+	w.Error(errors.Wrap(Errorf("app not found").WithHint("run apps").WithURL("https://example.org"))) //nolint:errcheck
+
+	got := stripColor(buf.String())
+
+	for _, want := range []string{"ERROR: app not found\n", "  hint: run apps\n", "  docs: https://example.org\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestEngineExecuteContextError(t *testing.T) {
+	buf := &bytes.Buffer{}
+	e := &Engine{
+		Name:    "testapp",
+		Version: "1.0.0",
+		Writer: &Writer{
+			Stdout: buf,
+			Stderr: buf,
+			Color:  false,
+			Tags:   DefaultWriter.Tags,
+		},
+	}
+
+	e.Command("test", "test command", func(ctx Context) error {
+		return Errorf("failed").WithExit(7).WithHint("try again")
+	}, CommandOptions{})
+
+	if code := e.ExecuteContext(context.Background(), []string{"test"}); code != 7 {
+		t.Errorf("exit code = %d, want 7", code)
+	}
+
+	got := stripColor(buf.String())
+
+	if !strings.Contains(got, "ERROR: failed") || !strings.Contains(got, "hint: try again") {
+		t.Errorf("output = %q, want error and hint", got)
+	}
+}
+
+func TestEngineExecuteContextErrorJSON(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	e := &Engine{
+		Name:    "testapp",
+		Version: "1.0.0",
+		Flags: []Flag{
+			StringFlag("output", "o", "output format"),
+		},
+		Writer: &Writer{
+			Stdout: stdout,
+			Stderr: stderr,
+			Color:  true,
+			Tags:   DefaultWriter.Tags,
+		},
+	}
+
+	e.Command("test", "test command", func(ctx Context) error {
+		return Errorf("failed").WithCode("E42").WithExit(4).WithHint("try again")
+	}, CommandOptions{})
+
+	e.Command("plain", "plain error", func(ctx Context) error {
+		return errors.Errorf("plain failure")
+	}, CommandOptions{})
+
+	if code := e.ExecuteContext(context.Background(), []string{"test", "--output", "json"}); code != 4 {
+		t.Errorf("exit code = %d, want 4", code)
+	}
+
+	if stdout.Len() != 0 {
+		t.Errorf("stdout = %q, want empty", stdout.String())
+	}
+
+	var got struct {
+		Error errorJSON `json:"error"`
+	}
+
+	if err := json.Unmarshal(stderr.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal stderr: %v, output: %s", err, stderr.String())
+	}
+
+	want := errorJSON{Code: "E42", ExitCode: 4, Hints: []string{"try again"}, Message: "failed"}
+
+	if !reflect.DeepEqual(got.Error, want) {
+		t.Errorf("error = %+v, want %+v", got.Error, want)
+	}
+
+	stderr.Reset()
+
+	if code := e.ExecuteContext(context.Background(), []string{"plain", "-o", "json"}); code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+
+	if err := json.Unmarshal(stderr.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal stderr: %v, output: %s", err, stderr.String())
+	}
+
+	if got.Error.Message != "plain failure" || got.Error.ExitCode != 1 || len(got.Error.Hints) != 0 {
+		t.Errorf("error = %+v", got.Error)
+	}
+}
+
+func TestEngineExecuteContextParseErrorJSON(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	e := &Engine{
+		Name:    "testapp",
+		Version: "1.0.0",
+		Flags: []Flag{
+			StringFlag("output", "o", "output format"),
+		},
+		Writer: &Writer{
+			Stdout: stdout,
+			Stderr: stderr,
+			Tags:   DefaultWriter.Tags,
+		},
+	}
+
+	e.Command("test", "test command", func(ctx Context) error {
+		return nil
+	}, CommandOptions{})
+
+	if code := e.ExecuteContext(context.Background(), []string{"test", "--output", "json", "--bogus"}); code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+
+	if stdout.Len() != 0 {
+		t.Errorf("stdout = %q, want empty", stdout.String())
+	}
+
+	var got struct {
+		Error errorJSON `json:"error"`
+	}
+
+	if err := json.Unmarshal(stderr.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal stderr: %v, output: %s", err, stderr.String())
+	}
+
+	if got.Error.Message != "unknown flag: --bogus" {
+		t.Errorf("message = %q, want %q", got.Error.Message, "unknown flag: --bogus")
+	}
+}
@@ -15,8 +15,17 @@ type Executor interface {
 	Terminal(ctx context.Context, cmd string, args ...string) error
 }
 
+// Piper is an optional interface for executors that can run a command
+// reading from r and writing to w, as the pager does. Executors that don't
+// implement it have those commands run with os/exec.
+type Piper interface {
+	Pipe(ctx context.Context, r io.Reader, w io.Writer, cmd string, args ...string) error
+}
+
 type defaultExecutor struct{}
 
+var _ Piper = &defaultExecutor{}
+
 func (e *defaultExecutor) Execute(ctx context.Context, cmd string, args ...string) ([]byte, error) {
 	data, err := exec.CommandContext(ctx, cmd, args...).CombinedOutput()
 	if err != nil {
@@ -26,6 +35,20 @@ func (e *defaultExecutor) Execute(ctx context.Context, cmd string, args ...strin
 	return data, nil
 }
 
+func (e *defaultExecutor) Pipe(ctx context.Context, r io.Reader, w io.Writer, cmd string, args ...string) error {
+	c := exec.CommandContext(ctx, cmd, args...)
+
+	c.Stdin = r
+	c.Stdout = w
+	c.Stderr = os.Stderr
+
+	if err := c.Run(); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
 func (e *defaultExecutor) Run(ctx context.Context, w io.Writer, cmd string, args ...string) error {
 	c := exec.CommandContext(ctx, cmd, args...)
 
@@ -27,7 +27,8 @@ type Flag struct {
 	Short       string
 	Value       any
 
-	kind FlagType
+	kind     FlagType
+	optional string
 }
 
 type Flags []*Flag
@@ -133,6 +134,20 @@ func (fs Flags) Bool(name string) bool {
 	return false
 }
 
+func (fs Flags) Duration(name string) time.Duration {
+	if f, ok := fs.find(name, FlagDuration); ok {
+		switch t := f.Value.(type) {
+		case nil:
+			v, _ := f.Default.(time.Duration)
+			return v
+		case time.Duration:
+			return t
+		}
+	}
+
+	return 0
+}
+
 func (fs Flags) Int(name string) int {
 	if f, ok := fs.find(name, FlagInt); ok {
 		switch t := f.Value.(type) {
@@ -0,0 +1,354 @@
+package stdcli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.ddollar.dev/errors"
+)
+
+// Formatter renders tables and info for an --output format. Keys are each
+// Column.Key, or the header in snake_case when it has none, and values have
+// their tags stripped.
+type Formatter interface {
+	FormatInfo(w io.Writer, keys []string, values []any) error
+	FormatTable(w io.Writer, keys []string, rows [][]any) error
+}
+
+// DefaultFormats are the --output formats available to every engine in
+// addition to json and text. Apps can add their own with Engine.Formats.
+var DefaultFormats = map[string]Formatter{
+	"csv":      csvFormatter{comma: ','},
+	"jsonl":    jsonlFormatter{},
+	"markdown": markdownFormatter{},
+	"tsv":      csvFormatter{comma: '\t'},
+	"yaml":     yamlFormatter{},
+}
+
+// rowFormatter is implemented by formatters that can write a table one row
+// at a time, which lets streamed tables write rows as they arrive.
+type rowFormatter interface {
+	formatHeader(w io.Writer, keys []string) error
+	formatRow(w io.Writer, keys []string, row []any) error
+}
+
+var (
+	_ rowFormatter = csvFormatter{}
+	_ rowFormatter = jsonlFormatter{}
+	_ rowFormatter = markdownFormatter{}
+	_ rowFormatter = yamlFormatter{}
+)
+
+// formatter returns the formatter for name, preferring the engine's own.
+func (e *Engine) formatter(name string) (Formatter, bool) {
+	if f, ok := e.Formats[name]; ok {
+		return f, true
+	}
+
+	f, ok := DefaultFormats[name]
+
+	return f, ok
+}
+
+func formatRows(f rowFormatter, w io.Writer, keys []string, rows [][]any) error {
+	if err := f.formatHeader(w, keys); err != nil {
+		return err //nowrap
+	}
+
+	for _, r := range rows {
+		if err := f.formatRow(w, keys, r); err != nil {
+			return err //nowrap
+		}
+	}
+
+	return nil
+}
+
+// plainValue strips tags and colors from string values.
+// formatText returns v as text for the formats that are not json, writing
+// times the same way json does.
+func formatText(v any) string {
+	if t, ok := v.(time.Time); ok {
+		return t.Format(time.RFC3339Nano)
+	}
+
+	return fmt.Sprintf("%v", v)
+}
+
+func plainValue(v any) any {
+	if s, ok := v.(string); ok {
+		return stripColor(stripTags(s))
+	}
+
+	return v
+}
+
+type csvFormatter struct {
+	comma rune
+}
+
+func (f csvFormatter) FormatInfo(w io.Writer, keys []string, values []any) error {
+	return f.FormatTable(w, keys, [][]any{values})
+}
+
+func (f csvFormatter) FormatTable(w io.Writer, keys []string, rows [][]any) error {
+	return formatRows(f, w, keys, rows)
+}
+
+func (f csvFormatter) formatHeader(w io.Writer, keys []string) error {
+	return f.write(w, keys)
+}
+
+func (f csvFormatter) formatRow(w io.Writer, keys []string, row []any) error {
+	record := make([]string, len(keys))
+
+	for i := range keys {
+		if i < len(row) && row[i] != nil {
+			record[i] = formatText(row[i])
+		}
+	}
+
+	return f.write(w, record)
+}
+
+func (f csvFormatter) write(w io.Writer, record []string) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = f.comma
+
+	if err := cw.Write(record); err != nil {
+		return errors.Wrap(err)
+	}
+
+	cw.Flush()
+
+	if err := cw.Error(); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+type jsonlFormatter struct{}
+
+func (jsonlFormatter) FormatInfo(w io.Writer, keys []string, values []any) error {
+	return jsonlFormatter{}.FormatTable(w, keys, [][]any{values})
+}
+
+func (f jsonlFormatter) FormatTable(w io.Writer, keys []string, rows [][]any) error {
+	return formatRows(f, w, keys, rows)
+}
+
+func (jsonlFormatter) formatHeader(w io.Writer, keys []string) error {
+	return nil
+}
+
+func (jsonlFormatter) formatRow(w io.Writer, keys []string, row []any) error {
+	data, err := orderedJSON(keys, row)
+	if err != nil {
+		return err //nowrap
+	}
+
+	if _, err := fmt.Fprintf(w, "%s\n", data); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+// orderedJSON marshals values as an object with keys in the given order.
+func orderedJSON(keys []string, values []any) ([]byte, error) {
+	buf := strings.Builder{}
+
+	buf.WriteString("{")
+
+	for i, k := range keys {
+		var v any
+
+		if i < len(values) {
+			v = values[i]
+		}
+
+		kd, err := json.Marshal(k)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+
+		vd, err := json.Marshal(v)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+
+		if i > 0 {
+			buf.WriteString(",")
+		}
+
+		fmt.Fprintf(&buf, "%s:%s", kd, vd)
+	}
+
+	buf.WriteString("}")
+
+	return []byte(buf.String()), nil
+}
+
+type markdownFormatter struct{}
+
+func (markdownFormatter) FormatInfo(w io.Writer, keys []string, values []any) error {
+	rows := make([][]any, len(keys))
+
+	for i, k := range keys {
+		rows[i] = []any{k, nil}
+
+		if i < len(values) {
+			rows[i][1] = values[i]
+		}
+	}
+
+	return markdownFormatter{}.FormatTable(w, []string{"key", "value"}, rows)
+}
+
+func (f markdownFormatter) FormatTable(w io.Writer, keys []string, rows [][]any) error {
+	return formatRows(f, w, keys, rows)
+}
+
+func (markdownFormatter) formatHeader(w io.Writer, keys []string) error {
+	header := markdownRow(keys, func(i int) any { return keys[i] })
+
+	if _, err := fmt.Fprintf(w, "%s\n|%s\n", header, strings.Repeat(" --- |", len(keys))); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (markdownFormatter) formatRow(w io.Writer, keys []string, row []any) error {
+	line := markdownRow(keys, func(i int) any {
+		if i < len(row) {
+			return row[i]
+		}
+		return nil
+	})
+
+	if _, err := fmt.Fprintf(w, "%s\n", line); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+func markdownRow(keys []string, value func(i int) any) string {
+	cells := make([]string, len(keys))
+
+	for i := range keys {
+		if v := value(i); v != nil {
+			cells[i] = markdownEscaper.Replace(formatText(v))
+		}
+	}
+
+	return "| " + strings.Join(cells, " | ") + " |"
+}
+
+type yamlFormatter struct{}
+
+func (yamlFormatter) FormatInfo(w io.Writer, keys []string, values []any) error {
+	if len(keys) == 0 {
+		if _, err := fmt.Fprintln(w, "{}"); err != nil {
+			return errors.Wrap(err)
+		}
+		return nil
+	}
+
+	if _, err := io.WriteString(w, yamlMapping(keys, values, "")); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (f yamlFormatter) FormatTable(w io.Writer, keys []string, rows [][]any) error {
+	if len(rows) == 0 {
+		if _, err := fmt.Fprintln(w, "[]"); err != nil {
+			return errors.Wrap(err)
+		}
+		return nil
+	}
+
+	return formatRows(f, w, keys, rows)
+}
+
+func (yamlFormatter) formatHeader(w io.Writer, keys []string) error {
+	return nil
+}
+
+func (yamlFormatter) formatRow(w io.Writer, keys []string, row []any) error {
+	item := yamlMapping(keys, row, "  ")
+
+	if _, err := fmt.Fprintf(w, "- %s", strings.TrimPrefix(item, "  ")); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func yamlMapping(keys []string, values []any, indent string) string {
+	buf := strings.Builder{}
+
+	for i, k := range keys {
+		var v any
+
+		if i < len(values) {
+			v = values[i]
+		}
+
+		fmt.Fprintf(&buf, "%s%s: %s\n", indent, yamlString(k), yamlScalar(v))
+	}
+
+	return buf.String()
+}
+
+func yamlScalar(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprintf("%v", t)
+	case string:
+		return yamlString(t)
+	case time.Time:
+		return yamlString(formatText(t))
+	case fmt.Stringer:
+		return yamlString(t.String())
+	default:
+		// json is valid yaml flow syntax
+		if data, err := json.Marshal(t); err == nil {
+			return string(data)
+		}
+		return yamlString(fmt.Sprintf("%v", t))
+	}
+}
+
+// yamlPlain only allows strings that start with a letter, underscore or
+// slash, so indicators like @ or & and anything that could load as a number,
+// timestamp or special float such as .inf are always quoted.
+var yamlPlain = regexp.MustCompile(`^[A-Za-z_/][A-Za-z0-9_./@() :,-]*$`)
+
+var yamlReserved = regexp.MustCompile(`^(?i:true|false|yes|no|on|off|y|n|null|~)$`)
+
+// yamlString returns s unquoted if it can't be mistaken for anything but a
+// string and double quoted otherwise, erring on the side of quoting.
+func yamlString(s string) string {
+	_, nerr := strconv.ParseFloat(s, 64)
+
+	if yamlPlain.MatchString(s) && !yamlReserved.MatchString(s) && nerr != nil && !strings.Contains(s, ": ") && !strings.HasSuffix(s, ":") && !strings.HasSuffix(s, " ") {
+		return s
+	}
+
+	return strconv.Quote(s)
+}
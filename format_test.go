@@ -0,0 +1,219 @@
+package stdcli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestTableWriterFormats(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"csv", "id,name,note\n1,web,\"a, b\"\n2,worker,true\n"},
+		{"tsv", "id\tname\tnote\n1\tweb\ta, b\n2\tworker\ttrue\n"},
+		{"jsonl", `{"id":1,"name":"web","note":"a, b"}` + "\n" + `{"id":2,"name":"worker","note":true}` + "\n"},
+		{"markdown", "| id | name | note |\n| --- | --- | --- |\n| 1 | web | a, b |\n| 2 | worker | true |\n"},
+		{"yaml", "- id: 1\n  name: web\n  note: a, b\n- id: 2\n  name: worker\n  note: true\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			ctx := testContext(buf, buf, map[string]string{"output": tt.output})
+
+			table := ctx.Table("<h1>ID</h1>", "Name", "Note")
+			table.Append(1, "<id>web</id>", "a, b")
+			table.Append(2, "worker", true)
+
+			if err := table.Print(); err != nil {
+				t.Fatalf("Print() error = %v", err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableWriterFormatsTime(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"csv", "name,created\nweb,2024-01-02T03:04:05Z\n"},
+		{"tsv", "name\tcreated\nweb\t2024-01-02T03:04:05Z\n"},
+		{"json", "[\n  {\n    \"name\": \"web\",\n    \"created\": \"2024-01-02T03:04:05Z\"\n  }\n]"},
+		{"jsonl", `{"name":"web","created":"2024-01-02T03:04:05Z"}` + "\n"},
+		{"markdown", "| name | created |\n| --- | --- |\n| web | 2024-01-02T03:04:05Z |\n"},
+		{"yaml", "- name: web\n  created: \"2024-01-02T03:04:05Z\"\n"},
+	}
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			ctx := testContext(buf, buf, map[string]string{"output": tt.output})
+
+			table := ctx.Table("Name", Column{Header: "Created", Type: ColumnTime})
+			table.Append("web", created)
+
+			if err := table.Print(); err != nil {
+				t.Fatalf("Print() error = %v", err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableWriterFormatsEmpty(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"csv", "id,name\n"},
+		{"jsonl", ""},
+		{"markdown", "| id | name |\n| --- | --- |\n"},
+		{"yaml", "[]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			ctx := testContext(buf, buf, map[string]string{"output": tt.output})
+
+			if err := ctx.Table("ID", "Name").Print(); err != nil {
+				t.Fatalf("Print() error = %v", err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInfoWriterFormats(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"csv", "name,status\nweb,running\n"},
+		{"tsv", "name\tstatus\nweb\trunning\n"},
+		{"jsonl", `{"name":"web","status":"running"}` + "\n"},
+		{"markdown", "| key | value |\n| --- | --- |\n| name | web |\n| status | running |\n"},
+		{"yaml", "name: web\nstatus: running\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			ctx := testContext(buf, buf, map[string]string{"output": tt.output})
+
+			info := ctx.Info()
+			info.Add("Name", "<id>web</id>")
+			info.Add("Status", "running")
+
+			if err := info.Print(); err != nil {
+				t.Fatalf("Print() error = %v", err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type testFormatter struct{}
+
+func (testFormatter) FormatInfo(w io.Writer, keys []string, values []any) error {
+	for i, k := range keys {
+		fmt.Fprintf(w, "%s=%v\n", k, values[i])
+	}
+	return nil
+}
+
+func (testFormatter) FormatTable(w io.Writer, keys []string, rows [][]any) error {
+	for _, r := range rows {
+		fmt.Fprintf(w, "%v\n", r)
+	}
+	return nil
+}
+
+func TestCustomFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ctx := testContext(buf, buf, map[string]string{"output": "custom"})
+	ctx.engine.Formats = map[string]Formatter{"custom": testFormatter{}}
+
+	table := ctx.Table("ID", "Name")
+	table.Append(1, "web")
+
+	if err := table.Print(); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+
+	info := ctx.Info()
+	info.Add("Name", "web")
+
+	if err := info.Print(); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+
+	if got, want := buf.String(), "[1 web]\nname=web\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestYAMLString(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"web", "web"},
+		{"us-east-1", "us-east-1"},
+		{"", `""`},
+		{"true", `"true"`},
+		{"No", `"No"`},
+		{"123", `"123"`},
+		{"1.5", `"1.5"`},
+		{"key: value", `"key: value"`},
+		{"#comment", `"#comment"`},
+		{"-dash", `"-dash"`},
+		{"line\nbreak", `"line\nbreak"`},
+		{"trailing ", `"trailing "`},
+		{"/usr/bin", "/usr/bin"},
+		{"user@example.com", "user@example.com"},
+		{"@foo", `"@foo"`},
+		{"&anchor", `"&anchor"`},
+		{"*alias", `"*alias"`},
+		{"!tag", `"!tag"`},
+		{"%directive", `"%directive"`},
+		{"2024-01-02", `"2024-01-02"`},
+		{"2024-01-02T15:04:05Z", `"2024-01-02T15:04:05Z"`},
+		{"0x1F", `"0x1F"`},
+		{"0o17", `"0o17"`},
+		{"1_000", `"1_000"`},
+		{"1:20", `"1:20"`},
+		{"1.0.0", `"1.0.0"`},
+		{".inf", `".inf"`},
+		{"-.inf", `"-.inf"`},
+		{".NaN", `".NaN"`},
+		{"Infinity", `"Infinity"`},
+		{"(paren)", `"(paren)"`},
+	}
+
+	for _, tt := range tests {
+		if got := yamlString(tt.s); got != tt.want {
+			t.Errorf("yamlString(%q) = %s, want %s", tt.s, got, tt.want)
+		}
+	}
+}
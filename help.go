@@ -64,5 +64,5 @@ func helpCommand(ctx Context, e *Engine, cmd *Command) {
 	e.Writer.Writef("<h2>DESCRIPTION</h2>\n  <value>%s</value>\n\n", cmd.Description)                        //nolint:errcheck
 
 	writeFlags(ctx, e, "OPTIONS", cmd.Flags)
-	writeFlags(ctx, e, "GLOBAL OPTIONS", e.Flags)
+	writeFlags(ctx, e, "GLOBAL OPTIONS", e.globalFlags(cmd))
 }
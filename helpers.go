@@ -6,6 +6,43 @@ import (
 	"golang.org/x/term"
 )
 
+// sizer can be implemented by writers that know their terminal size without
+// being an *os.File.
+type sizer interface {
+	Size() (int, int)
+}
+
+// terminal can be implemented by readers and writers that are terminals
+// without being an *os.File.
+type terminal interface {
+	IsTerminal() bool
+}
+
 func isTerminal(f *os.File) bool {
 	return term.IsTerminal(int(f.Fd()))
 }
+
+func isTerminalStream(v any) bool {
+	switch t := v.(type) {
+	case *os.File:
+		return isTerminal(t)
+	case terminal:
+		return t.IsTerminal()
+	default:
+		return false
+	}
+}
+
+// terminalSize returns the width and height of v if it is a terminal.
+func terminalSize(v any) (int, int, bool) {
+	switch t := v.(type) {
+	case *os.File:
+		w, h, err := term.GetSize(int(t.Fd()))
+		return w, h, err == nil
+	case sizer:
+		w, h := t.Size()
+		return w, h, w > 0 && h > 0
+	default:
+		return 0, 0, false
+	}
+}
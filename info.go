@@ -1,6 +1,7 @@
 package stdcli
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -8,40 +9,104 @@ import (
 
 type InfoWriter interface {
 	Add(header string, value any)
+	AddKey(key, header string, value any)
 	Print() error
+	Section(header string) InfoWriter
 }
 
 type infoWriter struct {
-	ctx  Context
+	ctx  *defaultContext
 	rows []infoRow
 }
 
 type infoRow struct {
-	header string
-	value  string
+	header  string
+	key     string
+	section *infoWriter
+	value   any
 }
 
+var (
+	_ InfoWriter     = &infoWriter{}
+	_ json.Marshaler = &infoWriter{}
+)
+
+// Add adds a row shown as header. Its json key is the header in snake_case.
 func (i *infoWriter) Add(header string, value any) {
-	i.rows = append(i.rows, infoRow{header: header, value: fmt.Sprintf("%v", value)})
+	i.AddKey(snakeCase(header), header, value)
+}
+
+// AddKey adds a row shown as header with its own json key.
+func (i *infoWriter) AddKey(key, header string, value any) {
+	i.rows = append(i.rows, infoRow{header: header, key: key, value: value})
+}
+
+// Section adds a nested group of rows shown under header. The section is
+// printed along with its parent.
+func (i *infoWriter) Section(header string) InfoWriter {
+	s := &infoWriter{ctx: i.ctx}
+
+	i.rows = append(i.rows, infoRow{header: header, key: snakeCase(header), section: s})
+
+	return s
 }
 
 func (i *infoWriter) Print() error {
-	switch i.ctx.Flags().String("output") {
-	case "json":
+	if format := i.ctx.Flags().String("format"); format != "" {
+		return i.printTemplate(format)
+	}
+
+	output := i.ctx.Flags().String("output")
+
+	if output == "json" {
 		return i.printJSON()
-	default:
-		return i.printText()
 	}
+
+	if f, ok := i.ctx.engine.formatter(output); ok {
+		return i.printFormat(f)
+	}
+
+	return i.printText("")
 }
 
-func (i *infoWriter) printJSON() error {
-	v := map[string]any{}
+// MarshalJSON writes the rows as an object in the order they were added,
+// with tags stripped from values.
+func (i *infoWriter) MarshalJSON() ([]byte, error) {
+	keys := make([]string, len(i.rows))
+	values := make([]any, len(i.rows))
 
-	for _, r := range i.rows {
-		v[strings.ToLower(r.header)] = r.value
+	for j, r := range i.rows {
+		keys[j] = r.key
+		values[j] = plainValue(r.value)
+
+		if r.section != nil {
+			values[j] = r.section
+		}
+	}
+
+	return orderedJSON(keys, values)
+}
+
+func (i *infoWriter) printFormat(f Formatter) error {
+	keys, values := i.keyValues("")
+
+	buf := &bytes.Buffer{}
+
+	if err := f.FormatInfo(buf, keys, values); err != nil {
+		return err //nowrap
+	}
+
+	if _, err := i.ctx.Write(buf.Bytes()); err != nil {
+		return err //nowrap
 	}
 
-	data, err := json.MarshalIndent(v, "", "  ")
+	return nil
+}
+
+func (i *infoWriter) printTemplate(format string) error {
+	keys, _ := i.keyValues("")
+
+	data, err := formatTemplate(format, keys, []map[string]any{i.templateItem()})
 	if err != nil {
 		return err //nowrap
 	}
@@ -53,27 +118,90 @@ func (i *infoWriter) printJSON() error {
 	return nil
 }
 
-func (i *infoWriter) printText() error {
-	f := i.formatString()
+// keyValues returns the keys and plain values of the rows with sections
+// flattened into dotted keys.
+func (i *infoWriter) keyValues(prefix string) ([]string, []any) {
+	keys := []string{}
+	values := []any{}
 
 	for _, r := range i.rows {
-		value := strings.Replace(r.value, "\n", fmt.Sprintf("\n%*s  ", i.headerWidth(), ""), -1)
-		i.ctx.Writef(f, strings.ToUpper(r.header), value) //nolint:errcheck
+		if r.section != nil {
+			sk, sv := r.section.keyValues(prefix + r.key + ".")
+			keys = append(keys, sk...)
+			values = append(values, sv...)
+			continue
+		}
+
+		keys = append(keys, prefix+r.key)
+		values = append(values, plainValue(r.value))
+	}
+
+	return keys, values
+}
+
+// templateItem returns the rows as a map with sections nested under their
+// keys.
+func (i *infoWriter) templateItem() map[string]any {
+	item := map[string]any{}
+
+	for _, r := range i.rows {
+		if r.section != nil {
+			item[r.key] = r.section.templateItem()
+		} else {
+			item[r.key] = plainValue(r.value)
+		}
+	}
+
+	return item
+}
+
+func (i *infoWriter) printJSON() error {
+	data, err := json.MarshalIndent(i, "", "  ")
+	if err != nil {
+		return err //nowrap
+	}
+
+	if _, err := i.ctx.Write(data); err != nil {
+		return err //nowrap
 	}
 
 	return nil
 }
 
-func (i *infoWriter) formatString() string {
-	return fmt.Sprintf("<h1>%%-%ds</h1>  <value>%%s</value>\n", i.headerWidth())
+func (i *infoWriter) printText(indent string) error {
+	w := i.headerWidth()
+
+	for _, r := range i.rows {
+		header := strings.ToUpper(r.header)
+
+		if r.section != nil {
+			i.ctx.Writef("%s<h2>%s</h2>\n", indent, header) //nolint:errcheck
+
+			if err := r.section.printText(indent + "  "); err != nil {
+				return err //nowrap
+			}
+
+			continue
+		}
+
+		padding := strings.Repeat(" ", w-textWidth(header))
+		value := strings.Replace(fmt.Sprintf("%v", r.value), "\n", fmt.Sprintf("\n%s%*s  ", indent, w, ""), -1)
+		i.ctx.Writef("%s<h1>%s%s</h1>  <value>%s</value>\n", indent, header, padding, value) //nolint:errcheck
+	}
+
+	return nil
 }
 
 func (i *infoWriter) headerWidth() int {
 	w := 0
 
 	for _, r := range i.rows {
-		if len(r.header) > w {
-			w = len(r.header)
+		if r.section != nil {
+			continue
+		}
+
+		if hw := textWidth(strings.ToUpper(r.header)); hw > w {
+			w = hw
 		}
 	}
 
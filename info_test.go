@@ -177,3 +177,110 @@ func TestInfoWriterAlignment(t *testing.T) {
 		t.Errorf("expected non-empty output")
 	}
 }
+
+func TestInfoWriterJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	outputFlag := StringFlag("output", "", "output format")
+	outputFlag.Value = "json"
+
+	ctx := &defaultContext{
+		Context: context.Background(),
+		flags:   Flags{&outputFlag},
+		engine: &Engine{
+			Writer: &Writer{Stdout: buf, Stderr: buf, Tags: map[string]Renderer{}},
+		},
+	}
+
+	info := ctx.Info()
+	info.Add("Name", "web")
+	info.Add("Count", 3)
+	info.Add("Public", true)
+	info.AddKey("created_at", "Created", "2024-01-02T03:04:05Z")
+	info.Add("ID", "<id>app-1</id>")
+
+	limits := info.Section("Limits")
+	limits.Add("Memory", 512)
+	limits.Add("Status", "<ok>running</ok>")
+	limits.Add("Ports", []int{80, 443})
+
+	info.Add("Tags", map[string]string{"env": "prod"})
+
+	if err := info.Print(); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+
+	want := `{
+  "name": "web",
+  "count": 3,
+  "public": true,
+  "created_at": "2024-01-02T03:04:05Z",
+  "id": "app-1",
+  "limits": {
+    "memory": 512,
+    "status": "running",
+    "ports": [
+      80,
+      443
+    ]
+  },
+  "tags": {
+    "env": "prod"
+  }
+}`
+
+	if got := buf.String(); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestInfoWriterSections(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	outputFlag := StringFlag("output", "", "output format")
+
+	ctx := &defaultContext{
+		Context: context.Background(),
+		flags:   Flags{&outputFlag},
+		engine: &Engine{
+			Writer: &Writer{Stdout: buf, Stderr: buf, Tags: map[string]Renderer{}},
+		},
+	}
+
+	info := ctx.Info()
+	info.Add("Name", "web")
+
+	limits := info.Section("Limits")
+	limits.Add("Memory", 512)
+	limits.Add("CPU", 0.5)
+
+	info.AddKey("created_at", "Created", "today")
+
+	if err := info.Print(); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+
+	want := strings.Join([]string{
+		"NAME     web",
+		"LIMITS",
+		"  MEMORY  512",
+		"  CPU     0.5",
+		"CREATED  today",
+		"",
+	}, "\n")
+
+	if got := stripTags(buf.String()); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	outputFlag.Value = "csv"
+
+	if err := info.Print(); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+
+	if got, want := buf.String(), "name,limits.memory,limits.cpu,created_at\nweb,512,0.5,today\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,191 @@
+package stdcli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.ddollar.dev/errors"
+)
+
+// tagHandler is a slog.Handler that renders records through a Writer's tags
+// on stderr.
+type tagHandler struct {
+	attrs  string
+	group  string
+	level  slog.Leveler
+	mu     *sync.Mutex
+	writer *Writer
+}
+
+var _ slog.Handler = &tagHandler{}
+
+func newTagHandler(w *Writer, level slog.Leveler) *tagHandler {
+	return &tagHandler{level: level, mu: &sync.Mutex{}, writer: w}
+}
+
+func (h *tagHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *tagHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := h.attrs
+
+	r.Attrs(func(a slog.Attr) bool {
+		attrs += formatLogAttr(h.group, a)
+		return true
+	})
+
+	var line string
+
+	switch {
+	case r.Level < slog.LevelInfo:
+		line = fmt.Sprintf("<debug>%-5s</debug> %s", r.Level, r.Message)
+	case r.Level < slog.LevelWarn:
+		line = fmt.Sprintf("<info>%-5s</info> %s", r.Level, r.Message)
+	case r.Level < slog.LevelError:
+		line = fmt.Sprintf("<warning>%-5s</warning> %s", r.Level, r.Message)
+	default:
+		line = fmt.Sprintf("<error>%s</error>", r.Message)
+	}
+
+	if attrs != "" {
+		line += fmt.Sprintf(" <info>%s</info>", strings.TrimSpace(attrs))
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, err := fmt.Fprintln(h.writer.Stderr, h.writer.renderTags(line)); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (h *tagHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	hh := *h
+
+	for _, a := range attrs {
+		hh.attrs += formatLogAttr(h.group, a)
+	}
+
+	return &hh
+}
+
+func (h *tagHandler) WithGroup(name string) slog.Handler {
+	hh := *h
+
+	if hh.group != "" {
+		hh.group += "."
+	}
+
+	hh.group += name
+
+	return &hh
+}
+
+func formatLogAttr(group string, a slog.Attr) string {
+	a.Value = a.Value.Resolve()
+
+	if a.Equal(slog.Attr{}) {
+		return ""
+	}
+
+	key := a.Key
+
+	if group != "" {
+		key = group + "." + key
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		s := ""
+		for _, ga := range a.Value.Group() {
+			s += formatLogAttr(key, ga)
+		}
+		return s
+	}
+
+	v := a.Value.String()
+
+	if strings.ContainsAny(v, " \t\n\"=") {
+		v = strconv.Quote(v)
+	}
+
+	return fmt.Sprintf(" %s=%s", key, v)
+}
+
+// multiHandler fans records out to several handlers.
+type multiHandler []slog.Handler
+
+func (m multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range m {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	for _, h := range m {
+		if h.Enabled(ctx, r.Level) {
+			if err := h.Handle(ctx, r.Clone()); err != nil {
+				return errors.Wrap(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	hs := make(multiHandler, len(m))
+
+	for i, h := range m {
+		hs[i] = h.WithAttrs(attrs)
+	}
+
+	return hs
+}
+
+func (m multiHandler) WithGroup(name string) slog.Handler {
+	hs := make(multiHandler, len(m))
+
+	for i, h := range m {
+		hs[i] = h.WithGroup(name)
+	}
+
+	return hs
+}
+
+// envLogLevel reads the log level from LOG_LEVEL, falling back to debug when
+// DEBUG=true and warn otherwise.
+func envLogLevel() slog.Level {
+	if v := os.Getenv("LOG_LEVEL"); v != "" {
+		if l, err := parseLogLevel(v); err == nil {
+			return l
+		}
+	}
+
+	if os.Getenv("DEBUG") == "true" {
+		return slog.LevelDebug
+	}
+
+	return slog.LevelWarn
+}
+
+func parseLogLevel(s string) (slog.Level, error) {
+	var l slog.Level
+
+	if err := l.UnmarshalText([]byte(s)); err != nil {
+		return l, errors.Errorf("invalid log level: %s", s)
+	}
+
+	return l, nil
+}
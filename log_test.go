@@ -0,0 +1,194 @@
+package stdcli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.ddollar.dev/errors"
+)
+
+func logEngine(stdout, stderr *bytes.Buffer, fn HandlerFunc) *Engine {
+	e := testEngine(stdout, stderr)
+
+	e.Command("test", "test command", fn, CommandOptions{})
+
+	return e
+}
+
+func logAll(ctx Context) error {
+	ctx.Logger().Debug("debug message", "key", "value")
+	ctx.Logger().Info("info message")
+	ctx.Logger().Warn("warn message", "path", "a b")
+	ctx.Logger().Error("error message")
+	return nil
+}
+
+func TestContextLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "default level is warn",
+			args:    []string{"test"},
+			want:    []string{"WARN  warn message path=\"a b\"\n", "ERROR: error message\n"},
+			notWant: []string{"debug message", "info message"},
+		},
+		{
+			name: "debug flag",
+			args: []string{"test", "--debug"},
+			want: []string{"DEBUG debug message key=value\n", "INFO  info message\n", "WARN  warn message"},
+		},
+		{
+			name:    "log-level flag",
+			args:    []string{"test", "--log-level", "info"},
+			want:    []string{"INFO  info message\n"},
+			notWant: []string{"debug message"},
+		},
+		{
+			name:    "LOG_LEVEL env",
+			args:    []string{"test"},
+			env:     map[string]string{"LOG_LEVEL": "error"},
+			want:    []string{"ERROR: error message\n"},
+			notWant: []string{"warn message"},
+		},
+		{
+			name: "DEBUG env",
+			args: []string{"test"},
+			env:  map[string]string{"DEBUG": "true"},
+			want: []string{"DEBUG debug message"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DEBUG", "")
+			t.Setenv("LOG_LEVEL", "")
+
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			e := logEngine(stdout, stderr, logAll)
+
+			if code := e.ExecuteContext(context.Background(), tt.args); code != 0 {
+				t.Fatalf("exit code = %d, output: %s", code, stderr.String())
+			}
+
+			if stdout.Len() != 0 {
+				t.Errorf("stdout = %q, want empty", stdout.String())
+			}
+
+			got := stderr.String()
+
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("stderr = %q, want it to contain %q", got, want)
+				}
+			}
+
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("stderr = %q, should not contain %q", got, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestContextLoggerInvalidLevel(t *testing.T) {
+	stderr := &bytes.Buffer{}
+
+	e := logEngine(&bytes.Buffer{}, stderr, logAll)
+
+	if code := e.ExecuteContext(context.Background(), []string{"test", "--log-level", "loud"}); code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+
+	if !strings.Contains(stderr.String(), "invalid log level: loud") {
+		t.Errorf("stderr = %q, want invalid log level error", stderr.String())
+	}
+}
+
+func TestContextLoggerFile(t *testing.T) {
+	stderr := &bytes.Buffer{}
+
+	e := logEngine(&bytes.Buffer{}, stderr, func(ctx Context) error {
+		ctx.Logger().With("app", "myapp").Debug("file only")
+		return nil
+	})
+
+	e.LogFile = filepath.Join(t.TempDir(), "test.log")
+
+	if code := e.ExecuteContext(context.Background(), []string{"test"}); code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+
+	if stderr.Len() != 0 {
+		t.Errorf("stderr = %q, want empty", stderr.String())
+	}
+
+	data, err := os.ReadFile(e.LogFile)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("invalid log line %q: %v", data, err)
+	}
+
+	if record["msg"] != "file only" || record["level"] != "DEBUG" || record["app"] != "myapp" {
+		t.Errorf("record = %v", record)
+	}
+}
+
+func TestContextLoggerGroups(t *testing.T) {
+	stderr := &bytes.Buffer{}
+
+	e := logEngine(&bytes.Buffer{}, stderr, func(ctx Context) error {
+		ctx.Logger().WithGroup("req").With("id", 1).Warn("slow", "ms", 250)
+		return nil
+	})
+
+	e.ExecuteContext(context.Background(), []string{"test"})
+
+	if got, want := stderr.String(), "WARN  slow req.id=1 req.ms=250\n"; got != want {
+		t.Errorf("stderr = %q, want %q", got, want)
+	}
+}
+
+func TestWriterErrorTrace(t *testing.T) {
+	t.Setenv("DEBUG", "true")
+
+	buf := &bytes.Buffer{}
+	w := &Writer{
+		Stdout: buf,
+		Stderr: buf,
+		Color:  false,
+		Tags:   DefaultWriter.Tags,
+	}
+
+	w.Error(errors.Errorf("traced")) //nolint:errcheck
+
+	got := buf.String()
+
+	if !strings.Contains(got, "ERROR: traced\n") {
+		t.Errorf("output = %q, want error message", got)
+	}
+
+	if !strings.Contains(got, "DEBUG trace frame=") {
+		t.Errorf("output = %q, want debug trace", got)
+	}
+}
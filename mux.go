@@ -0,0 +1,156 @@
+package stdcli
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+
+	"go.ddollar.dev/errors"
+)
+
+// Multiplexer interleaves the output of concurrent sources line by line,
+// prefixing each line with the name of its source.
+type Multiplexer interface {
+	Close() error
+	Writer(name string) io.WriteCloser
+}
+
+// muxColors are assigned to sources in the order they are added.
+var muxColors = []int{39, 214, 170, 78, 204, 141, 45, 221, 111, 167}
+
+type multiplexer struct {
+	mu      sync.Mutex
+	sources []*muxWriter
+	width   int
+	writer  *Writer
+}
+
+type muxWriter struct {
+	buf    []byte
+	closed bool
+	color  int
+	mux    *multiplexer
+	name   string
+}
+
+var (
+	_ Multiplexer    = &multiplexer{}
+	_ io.WriteCloser = &muxWriter{}
+)
+
+// Multiplex returns a multiplexer for concurrent output such as processes
+// started with Context.RunWriter. Names are padded to the widest name seen so far,
+// so passing them all up front keeps the prefixes aligned. Partial lines are
+// flushed when the handler returns.
+func (c *defaultContext) Multiplex(names ...string) Multiplexer {
+	m := &multiplexer{writer: c.engine.Writer}
+
+	for _, n := range names {
+		m.width = max(m.width, textWidth(n))
+	}
+
+	c.onClose(func() { m.Close() }) //nolint:errcheck
+
+	return m
+}
+
+// Close flushes any partial lines left by the sources.
+func (m *multiplexer) Close() error {
+	m.mu.Lock()
+	sources := append([]*muxWriter{}, m.sources...)
+	m.mu.Unlock()
+
+	for _, s := range sources {
+		if err := s.Close(); err != nil {
+			return err //nowrap
+		}
+	}
+
+	return nil
+}
+
+// Writer returns a line-buffered writer whose lines are prefixed with name.
+// Each call returns a new source with its own color.
+func (m *multiplexer) Writer(name string) io.WriteCloser {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w := &muxWriter{color: muxColors[len(m.sources)%len(muxColors)], mux: m, name: name}
+
+	m.sources = append(m.sources, w)
+	m.width = max(m.width, textWidth(name))
+
+	return w
+}
+
+func (w *muxWriter) Write(data []byte) (int, error) {
+	w.mux.mu.Lock()
+	defer w.mux.mu.Unlock()
+
+	if w.closed {
+		return 0, errors.Errorf("write to closed writer: %s", w.name)
+	}
+
+	w.buf = append(w.buf, data...)
+
+	i := bytes.LastIndexByte(w.buf, '\n')
+	if i < 0 {
+		return len(data), nil
+	}
+
+	lines := w.buf[:i+1]
+
+	if err := w.writeLines(lines); err != nil {
+		return 0, err //nowrap
+	}
+
+	w.buf = append([]byte{}, w.buf[i+1:]...)
+
+	return len(data), nil
+}
+
+// Close writes any partial line with a trailing newline.
+func (w *muxWriter) Close() error {
+	w.mux.mu.Lock()
+	defer w.mux.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	buf := append(w.buf, '\n')
+	w.buf = nil
+
+	return w.writeLines(buf)
+}
+
+// writeLines prefixes each newline terminated line in data and writes them
+// in a single write. The caller must hold the multiplexer lock.
+func (w *muxWriter) writeLines(data []byte) error {
+	label := w.name + strings.Repeat(" ", w.mux.width-textWidth(w.name)) + " |"
+	prefix := w.mux.writer.renderTags(RenderColors(w.color)(label)) + " "
+
+	out := strings.Builder{}
+
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if line == "" {
+			continue
+		}
+
+		out.WriteString(prefix)
+		out.WriteString(line)
+	}
+
+	if _, err := io.WriteString(w.mux.writer.Stdout, out.String()); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
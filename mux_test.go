@@ -0,0 +1,180 @@
+package stdcli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMultiplexer(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	m := testContext(buf, buf, nil).Multiplex("web", "worker")
+
+	web := m.Writer("web")
+	worker := m.Writer("worker")
+
+	fmt.Fprint(web, "listening")
+	fmt.Fprint(worker, "started\nwaiting for jobs\n")
+	fmt.Fprint(web, " on :5000\nready\npartial")
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	want := strings.Join([]string{
+		"worker | started",
+		"worker | waiting for jobs",
+		"web    | listening on :5000",
+		"web    | ready",
+		"web    | partial",
+	}, "\n") + "\n"
+
+	if got := buf.String(); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := fmt.Fprint(web, "more"); err == nil {
+		t.Errorf("Write() after Close() error = nil")
+	}
+}
+
+func TestMultiplexerColor(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	c := testContext(buf, buf, nil)
+	c.engine.Writer.Color = true
+
+	m := c.Multiplex()
+
+	fmt.Fprintln(m.Writer("a"), "one")
+	fmt.Fprintln(m.Writer("bb"), "two")
+
+	want := "\033[38;5;39ma |\033[0m one\n\033[38;5;214mbb |\033[0m two\n"
+
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestMultiplexerConcurrent(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	m := testContext(buf, buf, nil).Multiplex("a", "b", "c")
+
+	wg := sync.WaitGroup{}
+
+	for _, name := range []string{"a", "b", "c"} {
+		wg.Add(1)
+
+		go func(w io.Writer) {
+			defer wg.Done()
+
+			// split each line across writes so interleaving would show
+			for i := 0; i < 100; i++ {
+				fmt.Fprintf(w, "line ")
+				fmt.Fprintf(w, "%03d\n", i)
+			}
+		}(m.Writer(name))
+	}
+
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+
+	if len(lines) != 300 {
+		t.Fatalf("got %d lines, want 300", len(lines))
+	}
+
+	sort.Strings(lines)
+
+	for i, line := range lines {
+		if want := fmt.Sprintf("%s | line %03d", string(rune('a'+i/100)), i%100); line != want {
+			t.Fatalf("line %d = %q, want %q", i, line, want)
+		}
+	}
+}
+
+// testMuxExecutor writes numbered lines split across writes, as a process
+// flushing partial output would.
+type testMuxExecutor struct {
+	defaultExecutor
+}
+
+func (e *testMuxExecutor) Run(ctx context.Context, w io.Writer, cmd string, args ...string) error {
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(w, "%s ", cmd)
+		fmt.Fprintf(w, "%03d\n", i)
+	}
+
+	fmt.Fprintf(w, "%s done", cmd)
+
+	return nil
+}
+
+func TestMultiplexerRunWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	c := testContext(buf, buf, nil)
+	c.engine.Executor = &testMuxExecutor{}
+
+	m := c.Multiplex("a", "b", "c")
+
+	wg := sync.WaitGroup{}
+
+	for _, name := range []string{"a", "b", "c"} {
+		wg.Add(1)
+
+		go func(name string, w io.Writer) {
+			defer wg.Done()
+
+			if err := c.RunWriter(w, name); err != nil {
+				t.Errorf("RunWriter() error = %v", err)
+			}
+		}(name, m.Writer(name))
+	}
+
+	wg.Wait()
+
+	c.close()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+
+	if len(lines) != 303 {
+		t.Fatalf("got %d lines, want 303", len(lines))
+	}
+
+	sort.Strings(lines)
+
+	for i, line := range lines {
+		name, n := string(rune('a'+i/101)), i%101
+
+		want := fmt.Sprintf("%s | %s %03d", name, name, n)
+		if n == 100 {
+			want = fmt.Sprintf("%s | %s done", name, name)
+		}
+
+		if line != want {
+			t.Fatalf("line %d = %q, want %q", i, line, want)
+		}
+	}
+}
+
+func TestMultiplexerFlushOnClose(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	c := testContext(buf, buf, nil)
+
+	fmt.Fprint(c.Multiplex().Writer("web"), "no newline")
+
+	c.close()
+
+	if got, want := buf.String(), "web | no newline\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
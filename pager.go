@@ -0,0 +1,251 @@
+package stdcli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"go.ddollar.dev/errors"
+)
+
+const defaultPager = "less -FRX"
+
+// pagerTimeout is how long output is held back waiting to fill the screen
+// before it is written out directly, so slow output such as steps shows up.
+var pagerTimeout = 500 * time.Millisecond
+
+// pager buffers output until it no longer fits on the terminal and then
+// streams it through $PAGER. Output that fits is written out as-is on close,
+// or once pagerTimeout passes without filling the screen.
+type pager struct {
+	buf    bytes.Buffer
+	cmd    []string
+	ctx    context.Context
+	direct bool
+	done   chan error
+	height int
+	lines  int
+	mu     sync.Mutex
+	out    io.Writer
+	pipe   *io.PipeWriter
+	piper  Piper
+	timer  *time.Timer
+	width  int
+}
+
+var _ terminal = &pager{}
+
+func newPager(ctx context.Context, e *Engine, out io.Writer) *pager {
+	width, height, _ := terminalSize(out)
+
+	return &pager{
+		cmd:    pagerCommand(),
+		ctx:    ctx,
+		height: height,
+		out:    out,
+		piper:  e.piper(),
+		width:  width,
+	}
+}
+
+// pageable reports whether output for c should go through a pager. Commands
+// that set NoPager are only paged for --help.
+func (e *Engine) pageable(c *Command, cc *defaultContext, help bool) bool {
+	if c.NoPager && !help {
+		return false
+	}
+
+	if e.NoPager || cc.Flags().Bool("no-pager") || cc.Flags().Duration("watch") > 0 || e.Executor == nil {
+		return false
+	}
+
+	if !e.Writer.IsTerminal() {
+		return false
+	}
+
+	cmd := pagerCommand()
+
+	if cmd[0] == "cat" {
+		return false
+	}
+
+	// a missing pager would swallow the output
+	if _, ok := e.piper().(*defaultExecutor); ok {
+		if _, err := exec.LookPath(cmd[0]); err != nil {
+			return false
+		}
+	}
+
+	_, height, ok := terminalSize(e.Writer.Stdout)
+
+	return ok && height > 1
+}
+
+// piper returns the engine's executor if it can pipe and runs commands with
+// os/exec otherwise.
+func (e *Engine) piper() Piper {
+	if p, ok := e.Executor.(Piper); ok {
+		return p
+	}
+
+	return &defaultExecutor{}
+}
+
+func pagerCommand() []string {
+	if cmd := strings.Fields(os.Getenv("PAGER")); len(cmd) > 0 {
+		return cmd
+	}
+
+	return strings.Fields(defaultPager)
+}
+
+func (p *pager) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stopTimer()
+
+	if p.pipe == nil {
+		if p.direct {
+			return nil
+		}
+
+		p.direct = true
+
+		if _, err := p.out.Write(p.buf.Bytes()); err != nil {
+			return errors.Wrap(err)
+		}
+		return nil
+	}
+
+	p.pipe.Close()
+
+	return <-p.done
+}
+
+func (p *pager) IsTerminal() bool {
+	return true
+}
+
+func (p *pager) Size() (int, int) {
+	return p.width, p.height
+}
+
+func (p *pager) Write(data []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pipe != nil {
+		return p.write(data)
+	}
+
+	if p.direct {
+		if _, err := p.out.Write(data); err != nil {
+			return 0, errors.Wrap(err)
+		}
+		return len(data), nil
+	}
+
+	p.buf.Write(data)
+	p.lines += bytes.Count(data, []byte("\n"))
+
+	if p.timer == nil {
+		p.timer = time.AfterFunc(pagerTimeout, func() { p.bypass() }) //nolint:errcheck
+	}
+
+	// leave a line for the shell prompt
+	if p.lines < p.height-1 {
+		return len(data), nil
+	}
+
+	p.start()
+
+	if _, err := p.write(p.buf.Bytes()); err != nil {
+		return 0, err //nowrap
+	}
+
+	p.buf.Reset()
+
+	return len(data), nil
+}
+
+// reader returns the engine's reader after flushing any paged output, so
+// that text written before asking for input is visible. Every read of input
+// goes through it.
+func (c *defaultContext) reader() *Reader {
+	c.unpage()
+
+	return c.engine.Reader
+}
+
+// unpage stops paging before the handler waits for input or hands the
+// terminal to another command.
+func (c *defaultContext) unpage() {
+	if c.engine.Writer == nil {
+		return
+	}
+
+	if p, ok := c.engine.Writer.Stdout.(*pager); ok {
+		p.bypass() //nolint:errcheck
+	}
+}
+
+// bypass writes out anything buffered and stops paging, so that a prompt
+// written before reading input is visible.
+func (p *pager) bypass() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stopTimer()
+
+	if p.pipe != nil || p.direct {
+		return nil
+	}
+
+	p.direct = true
+
+	if _, err := p.out.Write(p.buf.Bytes()); err != nil {
+		return errors.Wrap(err)
+	}
+
+	p.buf.Reset()
+
+	return nil
+}
+
+func (p *pager) start() {
+	p.stopTimer()
+
+	r, w := io.Pipe()
+
+	p.done = make(chan error, 1)
+	p.pipe = w
+
+	go func() {
+		err := p.piper.Pipe(p.ctx, r, p.out, p.cmd[0], p.cmd[1:]...)
+		r.Close()
+		p.done <- err
+	}()
+}
+
+// stopTimer cancels the pending timeout. The caller must hold the lock.
+func (p *pager) stopTimer() {
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+}
+
+// write sends data to the pager, discarding it if the pager has already
+// been closed by the user.
+func (p *pager) write(data []byte) (int, error) {
+	if _, err := p.pipe.Write(data); err != nil && err != io.ErrClosedPipe {
+		return 0, errors.Wrap(err)
+	}
+
+	return len(data), nil
+}
@@ -0,0 +1,289 @@
+package stdcli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testTerminal struct {
+	bytes.Buffer
+	height int
+	width  int
+}
+
+func (t *testTerminal) IsTerminal() bool {
+	return true
+}
+
+func (t *testTerminal) Size() (int, int) {
+	return t.width, t.height
+}
+
+type testPagerExecutor struct {
+	defaultExecutor
+	cmd string
+}
+
+func (e *testPagerExecutor) Pipe(ctx context.Context, r io.Reader, w io.Writer, cmd string, args ...string) error {
+	e.cmd = strings.Join(append([]string{cmd}, args...), " ")
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "[paged]%s", data)
+	return err
+}
+
+func pagerEngine(lines int, opts CommandOptions) (*Engine, *testTerminal, *testPagerExecutor) {
+	stdout := &testTerminal{height: 10, width: 80}
+	executor := &testPagerExecutor{}
+
+	e := testEngine(stdout, &bytes.Buffer{})
+	e.Executor = executor
+
+	e.Command("test", "test command", func(ctx Context) error {
+		for i := 0; i < lines; i++ {
+			ctx.Writef("line %d\n", i)
+		}
+		return nil
+	}, opts)
+
+	return e, stdout, executor
+}
+
+func TestPagerShortOutput(t *testing.T) {
+	t.Setenv("PAGER", "")
+
+	e, stdout, executor := pagerEngine(3, CommandOptions{})
+
+	if code := e.ExecuteContext(context.Background(), []string{"test"}); code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+
+	if got, want := stdout.String(), "line 0\nline 1\nline 2\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	if executor.cmd != "" {
+		t.Errorf("pager ran: %q", executor.cmd)
+	}
+}
+
+func TestPagerLongOutput(t *testing.T) {
+	t.Setenv("PAGER", "")
+
+	e, stdout, executor := pagerEngine(20, CommandOptions{})
+
+	if code := e.ExecuteContext(context.Background(), []string{"test"}); code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+
+	if executor.cmd != "less -FRX" {
+		t.Errorf("pager = %q, want %q", executor.cmd, "less -FRX")
+	}
+
+	got := stdout.String()
+
+	if !strings.HasPrefix(got, "[paged]line 0\n") || !strings.HasSuffix(got, "line 19\n") {
+		t.Errorf("output = %q, want paged output", got)
+	}
+
+	if e.Writer.Stdout != stdout {
+		t.Errorf("stdout was not restored")
+	}
+}
+
+func TestPagerEnv(t *testing.T) {
+	t.Setenv("PAGER", "more -s")
+
+	e, _, executor := pagerEngine(20, CommandOptions{})
+
+	e.ExecuteContext(context.Background(), []string{"test"})
+
+	if executor.cmd != "more -s" {
+		t.Errorf("pager = %q, want %q", executor.cmd, "more -s")
+	}
+}
+
+func TestPagerDisabled(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  string
+		opts CommandOptions
+	}{
+		{"no-pager flag", []string{"test", "--no-pager"}, "", CommandOptions{}},
+		{"opted out", []string{"test"}, "", CommandOptions{NoPager: true}},
+		{"cat pager", []string{"test"}, "cat", CommandOptions{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PAGER", tt.env)
+
+			e, stdout, executor := pagerEngine(20, tt.opts)
+
+			e.ExecuteContext(context.Background(), tt.args)
+
+			if executor.cmd != "" {
+				t.Errorf("pager ran: %q", executor.cmd)
+			}
+
+			if strings.Contains(stdout.String(), "[paged]") || !strings.Contains(stdout.String(), "line 19\n") {
+				t.Errorf("output = %q, want unpaged output", stdout.String())
+			}
+		})
+	}
+}
+
+func TestPagerNotTerminal(t *testing.T) {
+	t.Setenv("PAGER", "")
+
+	stdout := &bytes.Buffer{}
+	executor := &testPagerExecutor{}
+
+	e := testEngine(stdout, stdout)
+	e.Executor = executor
+
+	e.Command("test", "test command", func(ctx Context) error {
+		for i := 0; i < 50; i++ {
+			ctx.Writef("line %d\n", i)
+		}
+		return nil
+	}, CommandOptions{})
+
+	e.ExecuteContext(context.Background(), []string{"test"})
+
+	if executor.cmd != "" {
+		t.Errorf("pager ran: %q", executor.cmd)
+	}
+}
+
+func TestPagerHelp(t *testing.T) {
+	t.Setenv("PAGER", "")
+
+	e, stdout, executor := pagerEngine(0, CommandOptions{NoPager: true})
+
+	for i := 0; i < 20; i++ {
+		e.Commands[1].Flags = append(e.Commands[1].Flags, BoolFlag(fmt.Sprintf("flag-%d", i), "", "a flag"))
+	}
+
+	e.ExecuteContext(context.Background(), []string{"test", "--help"})
+
+	if executor.cmd != "less -FRX" || !strings.HasPrefix(stdout.String(), "[paged]") {
+		t.Errorf("help was not paged: %q", stdout.String())
+	}
+}
+
+func TestPagerTimeout(t *testing.T) {
+	t.Setenv("PAGER", "")
+
+	timeout := pagerTimeout
+	pagerTimeout = time.Millisecond
+	defer func() { pagerTimeout = timeout }()
+
+	e, stdout, executor := pagerEngine(0, CommandOptions{})
+
+	var seen string
+
+	e.Commands[1].Handler = func(ctx Context) error {
+		ctx.Writef("step 1\n")
+		time.Sleep(20 * time.Millisecond)
+		ctx.Writef("step 2\n")
+		seen = stdout.String()
+		for i := 0; i < 20; i++ {
+			ctx.Writef("line %d\n", i)
+		}
+		return nil
+	}
+
+	e.ExecuteContext(context.Background(), []string{"test"})
+
+	if seen != "step 1\nstep 2\n" {
+		t.Errorf("output while running = %q, want slow output written out", seen)
+	}
+
+	if executor.cmd != "" || strings.Contains(stdout.String(), "[paged]") {
+		t.Errorf("output was paged after the timeout: %q", stdout.String())
+	}
+}
+
+// testTerminalReader is input that reports being a terminal.
+type testTerminalReader struct {
+	*strings.Reader
+}
+
+func (r testTerminalReader) IsTerminal() bool {
+	return true
+}
+
+func TestPagerRead(t *testing.T) {
+	tests := []struct {
+		name string
+		read func(Context) error
+	}{
+		{"read", func(ctx Context) error { _, err := ctx.Read(make([]byte, 1)); return err }},
+		{"read secret", func(ctx Context) error { _, err := ctx.ReadSecret(); return err }},
+		{"select", func(ctx Context) error { _, err := ctx.Select("Pick", []string{"a", "b"}, 0); return err }},
+		{"multi select", func(ctx Context) error { _, err := ctx.MultiSelect("Pick", []string{"a", "b"}, nil); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PAGER", "")
+
+			stdout := &testTerminal{height: 10, width: 80}
+			seen := []string{}
+
+			e := testEngine(stdout, &bytes.Buffer{})
+			e.Executor = &testPagerExecutor{}
+			e.Reader = &Reader{testTerminalReader{strings.NewReader("\r")}}
+
+			e.Command("test", "test command", func(ctx Context) error {
+				ctx.Writef("Name: ")
+				if err := tt.read(ctx); err != nil {
+					return err
+				}
+				seen = append(seen, stdout.String())
+				for i := 0; i < 20; i++ {
+					ctx.Writef("line %d\n", i)
+				}
+				return nil
+			}, CommandOptions{})
+
+			if code := e.ExecuteContext(context.Background(), []string{"test"}); code != 0 {
+				t.Fatalf("exit code = %d", code)
+			}
+
+			if len(seen) != 1 || seen[0] != "Name: " {
+				t.Errorf("output before read = %q, want %q", seen, "Name: ")
+			}
+
+			if strings.Contains(stdout.String(), "[paged]") {
+				t.Errorf("output was paged after a read: %q", stdout.String())
+			}
+		})
+	}
+}
+
+func TestEnginePiper(t *testing.T) {
+	pipe := &testPagerExecutor{}
+
+	if got := (&Engine{Executor: pipe}).piper(); got != pipe {
+		t.Errorf("piper() = %T, want the engine's executor", got)
+	}
+
+	// an executor that only implements Executor falls back to os/exec
+	plain := struct{ Executor }{&defaultExecutor{}}
+
+	if _, ok := (&Engine{Executor: plain}).piper().(*defaultExecutor); !ok {
+		t.Errorf("piper() did not fall back to os/exec")
+	}
+}
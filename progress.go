@@ -0,0 +1,220 @@
+package stdcli
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ProgressBar interface {
+	Add(n int)
+	Done()
+	Set(n int)
+}
+
+type Spinner interface {
+	Stop()
+	Update(label string)
+}
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const (
+	progressWidth   = 30
+	progressStep    = 25
+	spinnerInterval = 100 * time.Millisecond
+)
+
+type progressBar struct {
+	current  int
+	done     chan struct{}
+	mu       sync.Mutex
+	reported int
+	terminal bool
+	total    int
+	writer   *Writer
+}
+
+var _ ProgressBar = &progressBar{}
+
+// Progress returns a progress bar on stderr that is redrawn in place on a
+// terminal and otherwise reports every 25%. It finishes when Done is called,
+// the context is canceled or the handler returns.
+func (c *defaultContext) Progress(total int) ProgressBar {
+	p := &progressBar{
+		done:     make(chan struct{}),
+		reported: -1,
+		terminal: c.IsTerminalWriter(),
+		total:    total,
+		writer:   c.engine.Writer,
+	}
+
+	p.render()
+
+	c.stopWith(p.done, p.Done)
+
+	return p
+}
+
+func (p *progressBar) Add(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.set(p.current + n)
+}
+
+func (p *progressBar) Done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return
+	default:
+		close(p.done)
+	}
+
+	if p.terminal {
+		fmt.Fprint(p.writer.Stderr, "\n")
+	}
+}
+
+func (p *progressBar) Set(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.set(n)
+}
+
+func (p *progressBar) set(n int) {
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+
+	p.current = max(0, min(n, p.total))
+	p.render()
+}
+
+func (p *progressBar) percent() int {
+	if p.total <= 0 {
+		return 100
+	}
+
+	return p.current * 100 / p.total
+}
+
+func (p *progressBar) render() {
+	pct := p.percent()
+
+	if !p.terminal {
+		if step := pct / progressStep * progressStep; step > p.reported {
+			p.reported = step
+			fmt.Fprintf(p.writer.Stderr, "%s\n", p.writer.renderTags(fmt.Sprintf("<info>progress: %d%% (%d/%d)</info>", step, p.current, p.total)))
+		}
+		return
+	}
+
+	filled := pct * progressWidth / 100
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressWidth-filled)
+
+	fmt.Fprintf(p.writer.Stderr, "\r\033[K%s", p.writer.renderTags(fmt.Sprintf("[<ok>%s</ok>] <value>%3d%%</value> <info>%d/%d</info>", bar, pct, p.current, p.total)))
+}
+
+type spinner struct {
+	done   chan struct{}
+	frame  int
+	label  string
+	mu     sync.Mutex
+	writer *Writer
+}
+
+var _ Spinner = &spinner{}
+
+// Spinner shows an animated spinner with label on stderr when stdout is a
+// terminal and does nothing otherwise. It stops when Stop is called, the
+// context is canceled or the handler returns.
+func (c *defaultContext) Spinner(label string) Spinner {
+	s := &spinner{
+		done:   make(chan struct{}),
+		label:  label,
+		writer: c.engine.Writer,
+	}
+
+	if !c.IsTerminalWriter() {
+		close(s.done)
+		return s
+	}
+
+	s.render()
+
+	go s.spin()
+
+	c.stopWith(s.done, s.Stop)
+
+	return s
+}
+
+func (s *spinner) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return
+	default:
+		close(s.done)
+	}
+
+	fmt.Fprint(s.writer.Stderr, "\r\033[K")
+}
+
+func (s *spinner) Update(label string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.label = label
+}
+
+func (s *spinner) render() {
+	frame := spinnerFrames[s.frame%len(spinnerFrames)]
+
+	fmt.Fprintf(s.writer.Stderr, "\r\033[K%s", s.writer.renderTags(fmt.Sprintf("<ok>%s</ok> <value>%s</value>", frame, s.label)))
+}
+
+func (s *spinner) spin() {
+	t := time.NewTicker(spinnerInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-t.C:
+			s.mu.Lock()
+			select {
+			case <-s.done:
+			default:
+				s.frame++
+				s.render()
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// stopWith calls stop when the context is canceled or the handler returns,
+// unless done is closed first.
+func (c *defaultContext) stopWith(done chan struct{}, stop func()) {
+	c.onClose(stop)
+
+	go func() {
+		select {
+		case <-done:
+		case <-c.Done():
+			stop()
+		}
+	}()
+}
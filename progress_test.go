@@ -0,0 +1,134 @@
+package stdcli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressPlain(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	ctx := testContext(&bytes.Buffer{}, stderr, nil)
+
+	p := ctx.Progress(10)
+
+	for i := 0; i < 10; i++ {
+		p.Add(1)
+	}
+
+	p.Done()
+	p.Done()
+
+	want := strings.Join([]string{
+		"progress: 0% (0/10)",
+		"progress: 25% (3/10)",
+		"progress: 50% (5/10)",
+		"progress: 75% (8/10)",
+		"progress: 100% (10/10)",
+		"",
+	}, "\n")
+
+	if got := stderr.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestProgressTerminal(t *testing.T) {
+	stderr := &bytes.Buffer{}
+
+	p := &progressBar{
+		done:     make(chan struct{}),
+		terminal: true,
+		total:    4,
+		writer:   &Writer{Stderr: stderr, Tags: DefaultWriter.Tags},
+	}
+
+	p.Set(2)
+	p.Set(10)
+	p.Done()
+
+	got := stderr.String()
+
+	if !strings.Contains(got, "\r\033[K[===============               ]  50% 2/4") {
+		t.Errorf("output = %q, want half bar", got)
+	}
+
+	if !strings.HasSuffix(got, "\r\033[K[==============================] 100% 4/4\n") {
+		t.Errorf("output = %q, want full bar and newline", got)
+	}
+}
+
+func TestProgressStopsOnCancel(t *testing.T) {
+	cctx, cancel := context.WithCancel(context.Background())
+	stderr := &bytes.Buffer{}
+	ctx := testContext(&bytes.Buffer{}, stderr, nil)
+	ctx.Context = cctx
+
+	p := ctx.Progress(4).(*progressBar)
+
+	cancel()
+
+	select {
+	case <-p.done:
+	case <-time.After(time.Second):
+		t.Fatal("progress did not stop on cancel")
+	}
+
+	p.Add(4)
+
+	if got := stderr.String(); got != "progress: 0% (0/4)\n" {
+		t.Errorf("output = %q, want no updates after cancel", got)
+	}
+}
+
+func TestProgressStopsOnClose(t *testing.T) {
+	ctx := testContext(&bytes.Buffer{}, &bytes.Buffer{}, nil)
+
+	p := ctx.Progress(4).(*progressBar)
+
+	ctx.close()
+
+	select {
+	case <-p.done:
+	default:
+		t.Errorf("progress did not stop when the context closed")
+	}
+}
+
+func TestSpinnerPlain(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	ctx := testContext(&bytes.Buffer{}, stderr, nil)
+
+	s := ctx.Spinner("working")
+	s.Update("still working")
+	s.Stop()
+	s.Stop()
+
+	if stderr.Len() != 0 {
+		t.Errorf("output = %q, want empty", stderr.String())
+	}
+}
+
+func TestSpinnerTerminal(t *testing.T) {
+	stderr := &bytes.Buffer{}
+
+	s := &spinner{
+		done:   make(chan struct{}),
+		label:  "working",
+		writer: &Writer{Stderr: stderr, Tags: DefaultWriter.Tags},
+	}
+
+	s.render()
+	s.Update("deploying")
+	s.frame++
+	s.render()
+	s.Stop()
+
+	want := "\r\033[K⠋ working\r\033[K⠙ deploying\r\033[K"
+
+	if got := stderr.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,513 @@
+package stdcli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.ddollar.dev/errors"
+)
+
+type PromptOptions struct {
+	Default  string
+	Validate func(string) error
+}
+
+// Prompt asks for a line of text. An empty answer selects the default.
+func (c *defaultContext) Prompt(label string, opts PromptOptions) (string, error) {
+	for {
+		c.prompt("<value>%s</value>%s: ", label, promptDefault(opts.Default))
+
+		line, err := c.readLine(opts.Default != "")
+		if err != nil {
+			return "", err //nowrap
+		}
+
+		if line == "" {
+			line = opts.Default
+		}
+
+		if err := c.validate(line, opts.Validate); err != nil {
+			if c.IsTerminalReader() {
+				c.prompt("<error>%s</error>\n", err)
+				continue
+			}
+			return "", err //nowrap
+		}
+
+		return line, nil
+	}
+}
+
+type SecretOptions struct {
+	Confirm bool
+}
+
+// PromptSecret reads a secret, echoing * for each character on a terminal.
+// When the reader is not a terminal a single line is read instead.
+func (c *defaultContext) PromptSecret(label string, opts SecretOptions) (string, error) {
+	secret, err := c.readSecret(label)
+	if err != nil {
+		return "", err //nowrap
+	}
+
+	if opts.Confirm {
+		again, err := c.readSecret(strings.TrimSpace("Confirm " + strings.ToLower(label)))
+		if err != nil {
+			return "", err //nowrap
+		}
+
+		if again != secret {
+			return "", errors.Errorf("entries do not match")
+		}
+	}
+
+	return secret, nil
+}
+
+// Confirm asks a yes/no question. An empty answer selects def.
+func (c *defaultContext) Confirm(label string, def bool) (bool, error) {
+	choices := "y/N"
+
+	if def {
+		choices = "Y/n"
+	}
+
+	for {
+		c.prompt("<value>%s</value> <info>[%s]</info>: ", label, choices)
+
+		line, err := c.readLine(true)
+		if err != nil {
+			return false, err //nowrap
+		}
+
+		switch strings.ToLower(line) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+
+		if !c.IsTerminalReader() {
+			return false, errors.Errorf("invalid answer: %s", line)
+		}
+
+		c.prompt("<error>please answer yes or no</error>\n")
+	}
+}
+
+// Select asks for one of options and returns its index. On a terminal the
+// choice is made with the arrow keys; otherwise by number.
+func (c *defaultContext) Select(label string, options []string, def int) (int, error) {
+	if len(options) == 0 {
+		return 0, errors.Errorf("no options to select from")
+	}
+
+	if def < 0 || def >= len(options) {
+		def = 0
+	}
+
+	if c.IsTerminal() {
+		s := &selector{cursor: def, label: label, options: options}
+
+		if err := c.selectRaw(s); err != nil {
+			return 0, err //nowrap
+		}
+
+		return s.cursor, nil
+	}
+
+	for {
+		c.promptOptions(label, options)
+		c.prompt("<value>Select</value> <info>[1-%d]</info>%s: ", len(options), promptDefault(strconv.Itoa(def+1)))
+
+		line, err := c.readLine(true)
+		if err != nil {
+			return 0, err //nowrap
+		}
+
+		if line == "" {
+			return def, nil
+		}
+
+		is, err := parseSelection(line, len(options))
+		if err == nil && len(is) != 1 {
+			err = errors.Errorf("select a single option")
+		}
+		if err != nil {
+			if c.IsTerminalReader() {
+				c.prompt("<error>%s</error>\n", err)
+				continue
+			}
+			return 0, err //nowrap
+		}
+
+		return is[0], nil
+	}
+}
+
+// MultiSelect asks for any number of options and returns their indexes. On a
+// terminal options are toggled with space; otherwise they are given as a comma
+// separated list of numbers.
+func (c *defaultContext) MultiSelect(label string, options []string, defs []int) ([]int, error) {
+	if len(options) == 0 {
+		return nil, errors.Errorf("no options to select from")
+	}
+
+	if c.IsTerminal() {
+		s := &selector{label: label, multi: true, options: options, selected: map[int]bool{}}
+
+		for _, d := range defs {
+			if d >= 0 && d < len(options) {
+				s.selected[d] = true
+			}
+		}
+
+		if err := c.selectRaw(s); err != nil {
+			return nil, err //nowrap
+		}
+
+		return s.selection(), nil
+	}
+
+	def := []string{}
+
+	for _, d := range defs {
+		def = append(def, strconv.Itoa(d+1))
+	}
+
+	for {
+		c.promptOptions(label, options)
+		c.prompt("<value>Select</value> <info>[1-%d, comma separated]</info>%s: ", len(options), promptDefault(strings.Join(def, ",")))
+
+		line, err := c.readLine(true)
+		if err != nil {
+			return nil, err //nowrap
+		}
+
+		if line == "" {
+			return append([]int{}, defs...), nil
+		}
+
+		is, err := parseSelection(line, len(options))
+		if err != nil {
+			if c.IsTerminalReader() {
+				c.prompt("<error>%s</error>\n", err)
+				continue
+			}
+			return nil, err //nowrap
+		}
+
+		return is, nil
+	}
+}
+
+func (c *defaultContext) prompt(format string, args ...any) {
+	c.unpage()
+
+	fmt.Fprint(c.engine.Writer.Stderr, c.engine.Writer.renderTags(fmt.Sprintf(format, args...)))
+}
+
+func (c *defaultContext) promptOptions(label string, options []string) {
+	c.prompt("<value>%s</value>\n", label)
+
+	for i, o := range options {
+		c.prompt("  <info>%d)</info> %s\n", i+1, o)
+	}
+}
+
+// readLine reads an answer. When input runs out an empty answer is returned
+// if the prompt has a default, otherwise it is an error.
+func (c *defaultContext) readLine(hasDefault bool) (string, error) {
+	line, err := c.reader().ReadLine()
+	if err == io.EOF {
+		if !c.IsTerminalReader() {
+			c.prompt("\n")
+		}
+		if hasDefault {
+			return "", nil
+		}
+		if !c.IsTerminalReader() {
+			return "", errors.Errorf("input required but stdin is not a terminal")
+		}
+		return "", errors.Errorf("input required")
+	}
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
+func (c *defaultContext) readSecret(label string) (string, error) {
+	if label != "" {
+		c.prompt("<value>%s</value>: ", label)
+	}
+
+	if !c.IsTerminalReader() {
+		line, err := c.reader().ReadLine()
+		if err == io.EOF {
+			return "", errors.Errorf("input required but stdin is not a terminal")
+		}
+		if err != nil {
+			return "", errors.Wrap(err)
+		}
+		return line, nil
+	}
+
+	restore := c.reader().TerminalRaw()
+	defer restore()
+
+	return readMasked(c.reader(), c.engine.Writer.Stderr)
+}
+
+func (c *defaultContext) selectRaw(s *selector) error {
+	w := c.engine.Writer
+
+	restore := c.reader().TerminalRaw()
+	defer restore()
+
+	fmt.Fprint(w.Stderr, "\033[?25l")
+	defer fmt.Fprint(w.Stderr, "\033[?25h")
+
+	lines := 0
+
+	for {
+		if lines > 0 {
+			fmt.Fprintf(w.Stderr, "\r\033[%dA", lines)
+		}
+
+		out := s.render()
+
+		for _, l := range out {
+			fmt.Fprintf(w.Stderr, "\r\033[K%s\r\n", w.renderTags(l))
+		}
+
+		lines = len(out)
+
+		k, err := readKey(c.reader())
+		if err != nil {
+			return err //nowrap
+		}
+
+		done, err := s.key(k)
+		if err != nil {
+			return err //nowrap
+		}
+
+		if done {
+			fmt.Fprintf(w.Stderr, "\r\033[%dA\033[J", lines)
+			fmt.Fprintf(w.Stderr, "%s\r\n", w.renderTags(s.summary()))
+			return nil
+		}
+	}
+}
+
+func (c *defaultContext) validate(v string, fn func(string) error) error {
+	if fn == nil {
+		return nil
+	}
+
+	return fn(v)
+}
+
+type selector struct {
+	cursor   int
+	label    string
+	multi    bool
+	options  []string
+	selected map[int]bool
+}
+
+func (s *selector) key(k string) (bool, error) {
+	switch k {
+	case "up", "k":
+		s.cursor = (s.cursor + len(s.options) - 1) % len(s.options)
+	case "down", "j":
+		s.cursor = (s.cursor + 1) % len(s.options)
+	case "space":
+		if s.multi {
+			s.selected[s.cursor] = !s.selected[s.cursor]
+		}
+	case "enter":
+		return true, nil
+	case "ctrl-c":
+		return false, errors.Errorf("canceled")
+	}
+
+	return false, nil
+}
+
+func (s *selector) render() []string {
+	hint := "arrows to move, enter to select"
+
+	if s.multi {
+		hint = "arrows to move, space to toggle, enter to confirm"
+	}
+
+	lines := []string{fmt.Sprintf("<value>%s</value> <info>(%s)</info>", s.label, hint)}
+
+	for i, o := range s.options {
+		prefix := "  "
+
+		if i == s.cursor {
+			prefix = "<ok>></ok> "
+		}
+
+		if s.multi {
+			if s.selected[i] {
+				prefix += "<ok>[x]</ok> "
+			} else {
+				prefix += "[ ] "
+			}
+		}
+
+		if i == s.cursor {
+			lines = append(lines, fmt.Sprintf("%s<value>%s</value>", prefix, o))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s<info>%s</info>", prefix, o))
+		}
+	}
+
+	return lines
+}
+
+func (s *selector) selection() []int {
+	is := []int{}
+
+	for i, ok := range s.selected {
+		if ok {
+			is = append(is, i)
+		}
+	}
+
+	sort.Ints(is)
+
+	return is
+}
+
+func (s *selector) summary() string {
+	if !s.multi {
+		return fmt.Sprintf("<value>%s</value>: %s", s.label, s.options[s.cursor])
+	}
+
+	vs := []string{}
+
+	for _, i := range s.selection() {
+		vs = append(vs, s.options[i])
+	}
+
+	return fmt.Sprintf("<value>%s</value>: %s", s.label, strings.Join(vs, ", "))
+}
+
+// readMasked reads a line from a terminal in raw mode, echoing * for each
+// character and handling backspace.
+func readMasked(r io.Reader, w io.Writer) (string, error) {
+	data := []byte{}
+	b := make([]byte, 1)
+
+	for {
+		if _, err := io.ReadFull(r, b); err != nil {
+			return "", errors.Wrap(err)
+		}
+
+		switch b[0] {
+		case '\r', '\n':
+			fmt.Fprint(w, "\r\n")
+			return string(data), nil
+		case 3:
+			fmt.Fprint(w, "\r\n")
+			return "", errors.Errorf("canceled")
+		case 4:
+			if len(data) == 0 {
+				fmt.Fprint(w, "\r\n")
+				return "", errors.Errorf("input required")
+			}
+		case 8, 127:
+			if len(data) > 0 {
+				_, size := utf8.DecodeLastRune(data)
+				data = data[:len(data)-size]
+				fmt.Fprint(w, "\b \b")
+			}
+		default:
+			data = append(data, b[0])
+
+			// echo once per character rather than once per byte
+			if utf8.RuneStart(b[0]) {
+				fmt.Fprint(w, "*")
+			}
+		}
+	}
+}
+
+// readKey reads a single keypress from a terminal in raw mode.
+func readKey(r io.Reader) (string, error) {
+	b := make([]byte, 1)
+
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", errors.Wrap(err)
+	}
+
+	switch b[0] {
+	case 3:
+		return "ctrl-c", nil
+	case '\r', '\n':
+		return "enter", nil
+	case ' ':
+		return "space", nil
+	case 27:
+		seq := make([]byte, 2)
+		if _, err := io.ReadFull(r, seq); err != nil {
+			return "esc", nil
+		}
+		switch string(seq) {
+		case "[A":
+			return "up", nil
+		case "[B":
+			return "down", nil
+		}
+		return "esc", nil
+	}
+
+	return string(b), nil
+}
+
+func parseSelection(s string, max int) ([]int, error) {
+	is := []int{}
+	seen := map[int]bool{}
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+
+		if part == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 1 || n > max {
+			return nil, errors.Errorf("invalid selection: %s", part)
+		}
+
+		if !seen[n-1] {
+			is = append(is, n-1)
+			seen[n-1] = true
+		}
+	}
+
+	sort.Ints(is)
+
+	return is, nil
+}
+
+func promptDefault(def string) string {
+	if def == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(" <info>(%s)</info>", def)
+}
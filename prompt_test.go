@@ -0,0 +1,416 @@
+package stdcli
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.ddollar.dev/errors"
+)
+
+func TestContextPrompt(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		opts    PromptOptions
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "answer",
+			input: "myapp\n",
+			want:  "myapp",
+		},
+		{
+			name:  "empty answer uses default",
+			input: "\n",
+			opts:  PromptOptions{Default: "web"},
+			want:  "web",
+		},
+		{
+			name:  "no input uses default",
+			input: "",
+			opts:  PromptOptions{Default: "web"},
+			want:  "web",
+		},
+		{
+			name:    "no input without default",
+			input:   "",
+			wantErr: "input required but stdin is not a terminal",
+		},
+		{
+			name:  "valid answer",
+			input: "abc\n",
+			opts: PromptOptions{Validate: func(s string) error {
+				if len(s) < 3 {
+					return errors.Errorf("too short")
+				}
+				return nil
+			}},
+			want: "abc",
+		},
+		{
+			name:  "invalid answer",
+			input: "ab\n",
+			opts: PromptOptions{Validate: func(s string) error {
+				if len(s) < 3 {
+					return errors.Errorf("too short")
+				}
+				return nil
+			}},
+			wantErr: "too short",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := &bytes.Buffer{}
+			ctx := testContext(&bytes.Buffer{}, stderr, nil)
+			ctx.engine.Reader = &Reader{Reader: strings.NewReader(tt.input)}
+
+			got, err := ctx.Prompt("Name", tt.opts)
+
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Prompt() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Prompt() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Prompt() = %q, want %q", got, tt.want)
+			}
+
+			if !strings.HasPrefix(stderr.String(), "Name") {
+				t.Errorf("prompt = %q, want label", stderr.String())
+			}
+		})
+	}
+}
+
+func TestContextPromptMultiple(t *testing.T) {
+	ctx := testContext(&bytes.Buffer{}, &bytes.Buffer{}, nil)
+	ctx.engine.Reader = &Reader{Reader: strings.NewReader("first\nsecond\n")}
+
+	a, _ := ctx.Prompt("A", PromptOptions{})
+	b, _ := ctx.Prompt("B", PromptOptions{})
+
+	if a != "first" || b != "second" {
+		t.Errorf("Prompt() = %q, %q, want %q, %q", a, b, "first", "second")
+	}
+}
+
+func TestContextConfirm(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		def     bool
+		want    bool
+		wantErr bool
+	}{
+		{"yes", "y\n", false, true, false},
+		{"full yes", "YES\n", false, true, false},
+		{"no", "n\n", true, false, false},
+		{"empty uses default true", "\n", true, true, false},
+		{"empty uses default false", "\n", false, false, false},
+		{"no input uses default", "", true, true, false},
+		{"invalid", "maybe\n", false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := &bytes.Buffer{}
+			ctx := testContext(&bytes.Buffer{}, stderr, nil)
+			ctx.engine.Reader = &Reader{Reader: strings.NewReader(tt.input)}
+
+			got, err := ctx.Confirm("Continue?", tt.def)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Confirm() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("Confirm() = %v, want %v", got, tt.want)
+			}
+
+			if !strings.Contains(stderr.String(), "Continue?") {
+				t.Errorf("prompt = %q, want label", stderr.String())
+			}
+		})
+	}
+}
+
+func TestContextSelect(t *testing.T) {
+	options := []string{"us-east", "us-west", "eu-west"}
+
+	tests := []struct {
+		name    string
+		input   string
+		def     int
+		want    int
+		wantErr bool
+	}{
+		{"by number", "2\n", 0, 1, false},
+		{"empty uses default", "\n", 2, 2, false},
+		{"no input uses default", "", 1, 1, false},
+		{"out of range", "4\n", 0, 0, true},
+		{"not a number", "east\n", 0, 0, true},
+		{"multiple", "1,2\n", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := &bytes.Buffer{}
+			ctx := testContext(&bytes.Buffer{}, stderr, nil)
+			ctx.engine.Reader = &Reader{Reader: strings.NewReader(tt.input)}
+
+			got, err := ctx.Select("Region", options, tt.def)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Select() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("Select() = %d, want %d", got, tt.want)
+			}
+
+			if !strings.Contains(stderr.String(), "  2) us-west\n") {
+				t.Errorf("prompt = %q, want numbered options", stderr.String())
+			}
+		})
+	}
+}
+
+func TestContextMultiSelect(t *testing.T) {
+	options := []string{"web", "worker", "cron"}
+
+	tests := []struct {
+		name    string
+		input   string
+		defs    []int
+		want    []int
+		wantErr bool
+	}{
+		{"by numbers", "3, 1\n", nil, []int{0, 2}, false},
+		{"duplicates", "2,2\n", nil, []int{1}, false},
+		{"empty uses defaults", "\n", []int{1, 2}, []int{1, 2}, false},
+		{"no input uses defaults", "", []int{0}, []int{0}, false},
+		{"invalid", "1,x\n", nil, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := testContext(&bytes.Buffer{}, &bytes.Buffer{}, nil)
+			ctx.engine.Reader = &Reader{Reader: strings.NewReader(tt.input)}
+
+			got, err := ctx.MultiSelect("Services", options, tt.defs)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MultiSelect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MultiSelect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectRaw(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    string
+		multi   bool
+		cursor  int
+		want    []int
+		wantErr bool
+	}{
+		{"enter selects default", "\r", false, 1, []int{1}, false},
+		{"down", "\033[B\r", false, 0, []int{1}, false},
+		{"up wraps", "\033[A\r", false, 0, []int{2}, false},
+		{"vim keys", "jjk\r", false, 0, []int{1}, false},
+		{"ctrl-c cancels", "\x03", false, 0, nil, true},
+		{"toggle", " \033[B\033[B \r", true, 0, []int{0, 2}, false},
+		{"toggle twice", "  \r", true, 0, []int{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := &bytes.Buffer{}
+			ctx := testContext(&bytes.Buffer{}, stderr, nil)
+			ctx.engine.Reader = &Reader{Reader: strings.NewReader(tt.keys)}
+
+			s := &selector{cursor: tt.cursor, label: "Pick", multi: tt.multi, options: []string{"a", "b", "c"}, selected: map[int]bool{}}
+
+			err := ctx.selectRaw(s)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectRaw() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			got := []int{s.cursor}
+
+			if tt.multi {
+				got = s.selection()
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selection = %v, want %v", got, tt.want)
+			}
+
+			if !strings.Contains(stderr.String(), "Pick: ") {
+				t.Errorf("output = %q, want summary", stderr.String())
+			}
+		})
+	}
+}
+
+func TestReaderReadLine(t *testing.T) {
+	r := &Reader{Reader: strings.NewReader("one\r\ntwo\nthree")}
+
+	for _, want := range []string{"one", "two", "three"} {
+		got, err := r.ReadLine()
+		if err != nil {
+			t.Fatalf("ReadLine() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("ReadLine() = %q, want %q", got, want)
+		}
+	}
+
+	if _, err := r.ReadLine(); err == nil {
+		t.Errorf("ReadLine() error = nil, want EOF")
+	}
+}
+
+func TestContextReadSecret(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	ctx := testContext(&bytes.Buffer{}, stderr, nil)
+	ctx.engine.Reader = &Reader{Reader: strings.NewReader("s3cret\nignored\n")}
+
+	got, err := ctx.ReadSecret()
+	if err != nil {
+		t.Fatalf("ReadSecret() error = %v", err)
+	}
+
+	if got != "s3cret" {
+		t.Errorf("ReadSecret() = %q, want %q", got, "s3cret")
+	}
+
+	if stderr.Len() != 0 {
+		t.Errorf("stderr = %q, want empty", stderr.String())
+	}
+}
+
+func TestContextPromptSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		opts    SecretOptions
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "piped",
+			input: "hunter2\n",
+			want:  "hunter2",
+		},
+		{
+			name:  "confirmed",
+			input: "hunter2\nhunter2\n",
+			opts:  SecretOptions{Confirm: true},
+			want:  "hunter2",
+		},
+		{
+			name:    "confirmation mismatch",
+			input:   "hunter2\nhunter3\n",
+			opts:    SecretOptions{Confirm: true},
+			wantErr: "entries do not match",
+		},
+		{
+			name:    "no input",
+			input:   "",
+			wantErr: "input required but stdin is not a terminal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := &bytes.Buffer{}
+			ctx := testContext(&bytes.Buffer{}, stderr, nil)
+			ctx.engine.Reader = &Reader{Reader: strings.NewReader(tt.input)}
+
+			got, err := ctx.PromptSecret("Password", tt.opts)
+
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("PromptSecret() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("PromptSecret() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("PromptSecret() = %q, want %q", got, tt.want)
+			}
+
+			if !strings.HasPrefix(stderr.String(), "Password: ") {
+				t.Errorf("prompt = %q, want label", stderr.String())
+			}
+
+			if tt.opts.Confirm && !strings.Contains(stderr.String(), "Confirm password: ") {
+				t.Errorf("prompt = %q, want confirmation label", stderr.String())
+			}
+		})
+	}
+}
+
+func TestReadMasked(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		want     string
+		wantEcho string
+		wantErr  bool
+	}{
+		{"simple", "abc\r", "abc", "***\r\n", false},
+		{"backspace", "abx\x7fc\r", "abc", "***\b \b*\r\n", false},
+		{"backspace on empty", "\x7fa\r", "a", "*\r\n", false},
+		{"multibyte", "pässwörd\r", "pässwörd", "********\r\n", false},
+		{"multibyte backspace", "aö\x7f\r", "a", "**\b \b\r\n", false},
+		{"ctrl-c", "ab\x03", "", "**\r\n", true},
+		{"ctrl-d", "\x04", "", "\r\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			echo := &bytes.Buffer{}
+
+			got, err := readMasked(strings.NewReader(tt.input), echo)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readMasked() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("readMasked() = %q, want %q", got, tt.want)
+			}
+
+			if echo.String() != tt.wantEcho {
+				t.Errorf("echo = %q, want %q", echo.String(), tt.wantEcho)
+			}
+		})
+	}
+}
@@ -3,7 +3,9 @@ package stdcli
 import (
 	"io"
 	"os"
+	"strings"
 
+	"go.ddollar.dev/errors"
 	"golang.org/x/term"
 )
 
@@ -20,11 +22,7 @@ func init() {
 }
 
 func (r *Reader) IsTerminal() bool {
-	if f, ok := r.Reader.(*os.File); ok {
-		return isTerminal(f)
-	}
-
-	return false
+	return isTerminalStream(r.Reader)
 }
 
 func (r *Reader) TerminalRaw() func() bool {
@@ -49,3 +47,31 @@ func (r *Reader) TerminalRaw() func() bool {
 		return false
 	}
 }
+
+// ReadLine reads a single line without buffering past the newline so the
+// rest of the input remains available to later reads.
+func (r *Reader) ReadLine() (string, error) {
+	line := []byte{}
+	b := make([]byte, 1)
+
+	for {
+		n, err := r.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				break
+			}
+			line = append(line, b[0])
+		}
+		if err == io.EOF {
+			if len(line) == 0 {
+				return "", io.EOF
+			}
+			break
+		}
+		if err != nil {
+			return "", errors.Wrap(err)
+		}
+	}
+
+	return strings.TrimSuffix(string(line), "\r"), nil
+}
@@ -0,0 +1,217 @@
+// Package stdclitest runs stdcli applications in-process for tests.
+//
+// Golden files are updated by running the tests with -update. The flag is
+// declared by the test package so that it can share it with its own golden
+// files:
+//
+//	var _ = flag.Bool("update", false, "update golden files")
+package stdclitest
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.ddollar.dev/stdcli"
+)
+
+const (
+	defaultHeight = 24
+	defaultWidth  = 80
+)
+
+// Options control how Run sets up the engine's input and output.
+type Options struct {
+	// Color renders tags as ANSI colors instead of stripping them. The
+	// environment's NO_COLOR and FORCE_COLOR are ignored either way.
+	Color bool
+
+	// Context is passed to the engine, defaulting to context.Background.
+	Context context.Context
+
+	// Height and Width are the size of a terminal for TerminalOutput,
+	// defaulting to 24x80.
+	Height int
+	Width  int
+
+	// Pager lets terminal output go through the pager.
+	Pager bool
+
+	// Stdin is the scripted input, for example one answer per line for
+	// prompts.
+	Stdin string
+
+	// TerminalInput and TerminalOutput make stdin and stdout/stderr report
+	// themselves as terminals.
+	TerminalInput  bool
+	TerminalOutput bool
+}
+
+// Result is the outcome of a Run.
+type Result struct {
+	Code   int
+	Stderr string
+	Stdout string
+}
+
+// Run executes args against e with its reader and writer replaced by
+// in-memory streams, restoring them afterwards. Tags are rendered with the
+// engine's own tags. Run changes fields of e while it runs, so tests that
+// share an engine must not call it from parallel tests.
+func Run(e *stdcli.Engine, opts Options, args ...string) *Result {
+	ctx := opts.Context
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	height, width := opts.Height, opts.Width
+
+	if height == 0 {
+		height = defaultHeight
+	}
+
+	if width == 0 {
+		width = defaultWidth
+	}
+
+	stdout := &buffer{height: height, terminal: opts.TerminalOutput, width: width}
+	stderr := &buffer{height: height, terminal: opts.TerminalOutput, width: width}
+
+	tags := stdcli.DefaultWriter.Tags
+
+	if e.Writer != nil {
+		tags = e.Writer.Tags
+	}
+
+	var stdin io.Reader = strings.NewReader(opts.Stdin)
+
+	if opts.TerminalInput {
+		stdin = &terminalReader{stdin}
+	}
+
+	reader, writer, noPager, colorMode := e.Reader, e.Writer, e.NoPager, e.ColorMode
+
+	defer func() {
+		e.Reader, e.Writer, e.NoPager, e.ColorMode = reader, writer, noPager, colorMode
+	}()
+
+	e.ColorMode = "never"
+
+	if opts.Color {
+		e.ColorMode = "always"
+	}
+
+	e.Reader = &stdcli.Reader{Reader: stdin}
+	e.Writer = &stdcli.Writer{Color: opts.Color, Stderr: stderr, Stdout: stdout, Tags: tags}
+	e.NoPager = noPager || !opts.Pager
+
+	code := e.ExecuteContext(ctx, args)
+
+	return &Result{Code: code, Stderr: stderr.String(), Stdout: stdout.String()}
+}
+
+// String returns the exit code, stdout and stderr as a transcript for
+// golden files.
+func (r *Result) String() string {
+	return fmt.Sprintf("exit: %d\n--- stdout\n%s--- stderr\n%s", r.Code, r.Stdout, r.Stderr)
+}
+
+// Golden compares the transcript of r with testdata/name.golden.
+func (r *Result) Golden(t testing.TB, name string) {
+	t.Helper()
+
+	Golden(t, name, r.String())
+}
+
+// Golden compares got with testdata/name.golden. Running the tests with
+// -update, or with UPDATE_GOLDEN=1 set, writes got to the file instead.
+func Golden(t testing.TB, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+
+	if update() {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("could not create golden directory: %v", err)
+		}
+
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatalf("could not update golden file: %v", err)
+		}
+
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read golden file, run with -update to create it: %v", err)
+	}
+
+	if got != string(want) {
+		t.Errorf("output does not match %s, run with -update to accept it\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// update reports whether golden files should be written, which is looked up
+// on each call as flags are parsed after package initialization.
+func update() bool {
+	if f := flag.Lookup("update"); f != nil {
+		if v, err := strconv.ParseBool(f.Value.String()); err == nil && v {
+			return true
+		}
+	}
+
+	return os.Getenv("UPDATE_GOLDEN") != ""
+}
+
+// buffer collects output and can pretend to be a terminal of a given size.
+type buffer struct {
+	buf      bytes.Buffer
+	height   int
+	mu       sync.Mutex
+	terminal bool
+	width    int
+}
+
+func (b *buffer) IsTerminal() bool {
+	return b.terminal
+}
+
+func (b *buffer) Size() (int, int) {
+	if !b.terminal {
+		return 0, 0
+	}
+
+	return b.width, b.height
+}
+
+func (b *buffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func (b *buffer) Write(data []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(data)
+}
+
+type terminalReader struct {
+	io.Reader
+}
+
+func (r *terminalReader) IsTerminal() bool {
+	return true
+}
@@ -0,0 +1,221 @@
+package stdclitest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.ddollar.dev/stdcli"
+)
+
+var _ = flag.Bool("update", false, "update golden files")
+
+func testEngine() *stdcli.Engine {
+	e := stdcli.New("testapp", "1.0.0")
+
+	e.Command("greet", "greet someone", func(ctx stdcli.Context) error {
+		name, err := ctx.Prompt("Name", stdcli.PromptOptions{
+			Validate: func(s string) error {
+				if s == "" {
+					return stdcli.Errorf("name is required")
+				}
+				return nil
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		ctx.Writef("<h1>hello</h1> %s\n", name)
+
+		return nil
+	}, stdcli.CommandOptions{})
+
+	e.Command("terminal", "report terminals", func(ctx stdcli.Context) error {
+		ctx.Writef("reader=%t writer=%t\n", ctx.IsTerminalReader(), ctx.IsTerminalWriter())
+		return nil
+	}, stdcli.CommandOptions{})
+
+	e.Command("list", "list things", func(ctx stdcli.Context) error {
+		t := ctx.Table("Name", "Description")
+		t.Truncate(1)
+		t.Append("web", "serves requests for the public website and its api")
+		t.Append("worker", "processes jobs")
+		return t.Print()
+	}, stdcli.CommandOptions{})
+
+	e.Command("fail", "fail with a warning", func(ctx stdcli.Context) error {
+		ctx.Warnf("something looks off")
+		return stdcli.Errorf("failed").WithExit(3)
+	}, stdcli.CommandOptions{})
+
+	return e
+}
+
+func TestRun(t *testing.T) {
+	r := Run(testEngine(), Options{}, "fail")
+
+	if r.Code != 3 {
+		t.Errorf("Code = %d, want 3", r.Code)
+	}
+
+	if r.Stdout != "" {
+		t.Errorf("Stdout = %q, want empty", r.Stdout)
+	}
+
+	if want := "WARNING: something looks off\nERROR: failed\n"; r.Stderr != want {
+		t.Errorf("Stderr = %q, want %q", r.Stderr, want)
+	}
+}
+
+func TestRunStdin(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       Options
+		wantCode   int
+		wantStdout string
+	}{
+		{
+			name:       "answer",
+			opts:       Options{Stdin: "ddollar\n"},
+			wantStdout: "hello ddollar\n",
+		},
+		{
+			name:     "invalid answer",
+			opts:     Options{Stdin: "\nddollar\n"},
+			wantCode: 1,
+		},
+		{
+			name:       "terminal retries invalid answer",
+			opts:       Options{Stdin: "\nddollar\n", TerminalInput: true},
+			wantStdout: "hello ddollar\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Run(testEngine(), tt.opts, "greet")
+
+			if r.Code != tt.wantCode {
+				t.Errorf("Code = %d, want %d, stderr: %s", r.Code, tt.wantCode, r.Stderr)
+			}
+
+			if r.Stdout != tt.wantStdout {
+				t.Errorf("Stdout = %q, want %q", r.Stdout, tt.wantStdout)
+			}
+
+			if !strings.HasPrefix(r.Stderr, "Name: ") {
+				t.Errorf("Stderr = %q, want prompt", r.Stderr)
+			}
+		})
+	}
+}
+
+func TestRunTerminal(t *testing.T) {
+	tests := []struct {
+		opts Options
+		want string
+	}{
+		{Options{}, "reader=false writer=false\n"},
+		{Options{TerminalInput: true}, "reader=true writer=false\n"},
+		{Options{TerminalOutput: true}, "reader=false writer=true\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.TrimSpace(tt.want), func(t *testing.T) {
+			if r := Run(testEngine(), tt.opts, "terminal"); r.Stdout != tt.want {
+				t.Errorf("Stdout = %q, want %q", r.Stdout, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunColor(t *testing.T) {
+	tests := []struct {
+		name  string
+		color bool
+		env   string
+		value string
+	}{
+		{"color", true, "", ""},
+		{"color with NO_COLOR", true, "NO_COLOR", "1"},
+		{"no color", false, "", ""},
+		{"no color with FORCE_COLOR", false, "FORCE_COLOR", "1"},
+		{"no color with CLICOLOR_FORCE", false, "CLICOLOR_FORCE", "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv(tt.env, tt.value)
+			}
+
+			r := Run(testEngine(), Options{Color: tt.color, Stdin: "x\n"}, "greet")
+
+			if got := strings.Contains(r.Stdout, "\033["); got != tt.color {
+				t.Errorf("Stdout = %q, want color %t", r.Stdout, tt.color)
+			}
+		})
+	}
+}
+
+func TestRunRestoresEngine(t *testing.T) {
+	e := testEngine()
+
+	reader, writer := e.Reader, e.Writer
+
+	Run(e, Options{}, "terminal")
+
+	if e.Reader != reader || e.Writer != writer || e.NoPager {
+		t.Errorf("Run() did not restore the engine")
+	}
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T)
+	}{
+		{"flag", func(t *testing.T) {
+			if err := flag.Set("update", "true"); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { flag.Set("update", "false") }) //nolint:errcheck
+		}},
+		{"env", func(t *testing.T) { t.Setenv("UPDATE_GOLDEN", "1") }},
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.Chdir(t.TempDir()); err != nil {
+				t.Fatal(err)
+			}
+
+			defer os.Chdir(dir) //nolint:errcheck
+
+			tt.setup(t)
+
+			Golden(t, "nested/output", "hello\n")
+
+			data, err := os.ReadFile(filepath.Join("testdata", "nested", "output.golden"))
+			if err != nil {
+				t.Fatalf("golden file was not written: %v", err)
+			}
+
+			if string(data) != "hello\n" {
+				t.Errorf("golden file = %q, want %q", data, "hello\n")
+			}
+		})
+	}
+}
+
+func TestGolden(t *testing.T) {
+	Run(testEngine(), Options{TerminalOutput: true, Width: 40}, "list").Golden(t, "list")
+}
@@ -0,0 +1,96 @@
+package stdcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type step struct {
+	open bool
+}
+
+type stepEvent struct {
+	Depth   int     `json:"depth"`
+	Elapsed float64 `json:"elapsed"`
+	Error   string  `json:"error,omitempty"`
+	Event   string  `json:"event"`
+	Status  string  `json:"status"`
+	Step    string  `json:"step"`
+}
+
+// Step prints label, runs fn and then reports OK or the error on the same
+// line along with the elapsed time if it was noticeable. Steps started inside
+// fn are nested underneath. With --output json each result is written to
+// stderr as a JSON event instead.
+func (c *defaultContext) Step(label string, fn func() error) error {
+	depth := len(c.steps)
+	indent := strings.Repeat("  ", depth)
+	jsonOutput := c.Flags().String("output") == "json"
+
+	s := &step{}
+
+	if !jsonOutput {
+		if depth > 0 && c.steps[depth-1].open {
+			c.Writef("\n")
+			c.steps[depth-1].open = false
+		}
+
+		c.Writef("%s<start>%s...</start>", indent, label)
+		s.open = true
+	}
+
+	c.steps = append(c.steps, s)
+
+	start := time.Now()
+	err := fn()
+	elapsed := time.Since(start)
+
+	c.steps = c.steps[:depth]
+
+	if jsonOutput {
+		c.stepEvent(label, depth, elapsed, err)
+		return err //nowrap
+	}
+
+	result := "<ok>OK</ok>"
+
+	if err != nil {
+		result = fmt.Sprintf("<error>%s</error>", err)
+	}
+
+	if elapsed >= time.Second {
+		result += fmt.Sprintf(" <info>(%s)</info>", elapsed.Round(100*time.Millisecond))
+	}
+
+	if s.open {
+		c.Writef(" %s\n", result)
+	} else {
+		c.Writef("%s%s\n", indent, result)
+	}
+
+	return err //nowrap
+}
+
+func (c *defaultContext) stepEvent(label string, depth int, elapsed time.Duration, err error) {
+	ev := stepEvent{
+		Depth:   depth,
+		Elapsed: elapsed.Seconds(),
+		Event:   "step",
+		Status:  "ok",
+		Step:    label,
+	}
+
+	if err != nil {
+		ev.Error = err.Error()
+		ev.Status = "error"
+	}
+
+	data, jerr := json.Marshal(ev)
+	if jerr != nil {
+		return
+	}
+
+	fmt.Fprintf(c.engine.Writer.Stderr, "%s\n", data)
+}
@@ -0,0 +1,110 @@
+package stdcli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.ddollar.dev/errors"
+)
+
+func TestContextStep(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	ctx := testContext(stdout, &bytes.Buffer{}, nil)
+
+	err := ctx.Step("Creating app", func() error {
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("Step() error = %v", err)
+	}
+
+	if got, want := stdout.String(), "Creating app... OK\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestContextStepError(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	ctx := testContext(stdout, &bytes.Buffer{}, nil)
+
+	err := ctx.Step("Creating app", func() error {
+		return errors.Errorf("quota exceeded")
+	})
+
+	if err == nil || err.Error() != "quota exceeded" {
+		t.Errorf("Step() error = %v, want quota exceeded", err)
+	}
+
+	if got, want := stdout.String(), "Creating app... ERROR: quota exceeded\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestContextStepNested(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	ctx := testContext(stdout, &bytes.Buffer{}, nil)
+
+	ctx.Step("Deploying", func() error { //nolint:errcheck
+		ctx.Step("Building", func() error { return nil })  //nolint:errcheck
+		ctx.Step("Releasing", func() error { return nil }) //nolint:errcheck
+		return nil
+	})
+
+	ctx.Step("Cleaning up", func() error { return nil }) //nolint:errcheck
+
+	want := strings.Join([]string{
+		"Deploying...",
+		"  Building... OK",
+		"  Releasing... OK",
+		"OK",
+		"Cleaning up... OK",
+		"",
+	}, "\n")
+
+	if got := stdout.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestContextStepJSON(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	ctx := testContext(stdout, stderr, map[string]string{"output": "json"})
+
+	ctx.Step("Deploying", func() error { //nolint:errcheck
+		return ctx.Step("Building", func() error {
+			return errors.Errorf("build failed")
+		})
+	})
+
+	if stdout.Len() != 0 {
+		t.Errorf("stdout = %q, want empty", stdout.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+
+	if len(lines) != 2 {
+		t.Fatalf("got %d events, want 2: %q", len(lines), stderr.String())
+	}
+
+	want := []stepEvent{
+		{Depth: 1, Error: "build failed", Event: "step", Status: "error", Step: "Building"},
+		{Depth: 0, Error: "build failed", Event: "step", Status: "error", Step: "Deploying"},
+	}
+
+	for i, line := range lines {
+		var got stepEvent
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("invalid event %q: %v", line, err)
+		}
+
+		got.Elapsed = 0
+
+		if got != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package stdcli
+
+import (
+	"bytes"
+	"encoding/json"
+	"text/template"
+
+	"go.ddollar.dev/ddl"
+	"go.ddollar.dev/errors"
+)
+
+// TableStream writes a table row by row as results arrive instead of
+// buffering it. Close must be called once the last row has been appended.
+type TableStream interface {
+	Append(row ...any) error
+	Close() error
+	Sample(rows int)
+	Truncate(columns ...int)
+	Wrap(columns ...int)
+}
+
+// defaultStreamSample is how many rows are buffered to measure column widths
+// before a streamed table starts writing text.
+const defaultStreamSample = 20
+
+type tableStream struct {
+	ctx      *defaultContext
+	closed   bool
+	columns  []any
+	count    int
+	cw       *columnWriter
+	limits   []int
+	rows     [][]any
+	sample   int
+	started  bool
+	template *template.Template
+	truncate []int
+	widths   []int
+	wrap     []int
+}
+
+var _ TableStream = &tableStream{}
+
+// TableStream returns a writer for a table that is written as rows are
+// appended. Text output measures column widths from the first rows and any
+// Column.MinWidth, json is written as an array one object at a time and
+// --filter and --columns are applied to each row. --sort is not supported.
+func (c *defaultContext) TableStream(columns ...any) TableStream {
+	return &tableStream{ctx: c, columns: columns, sample: defaultStreamSample}
+}
+
+func (s *tableStream) Append(row ...any) error {
+	if s.closed {
+		return errors.Errorf("table stream is closed")
+	}
+
+	v, err := s.view([][]any{row})
+	if err != nil {
+		return err //nowrap
+	}
+
+	for _, r := range v.rows {
+		if err := s.write(v, r); err != nil {
+			return err //nowrap
+		}
+
+		s.count++
+	}
+
+	return nil
+}
+
+func (s *tableStream) Close() error {
+	if s.closed {
+		return nil
+	}
+
+	s.closed = true
+
+	v, err := s.view(nil)
+	if err != nil {
+		return err //nowrap
+	}
+
+	// buffered rows have already been filtered
+	v.rows = s.rows
+
+	switch {
+	case s.ctx.Flags().String("format") != "":
+		return nil
+	case s.output() == "json":
+		return s.writeString(ddl.If(s.count == 0, "[]", "\n]"))
+	}
+
+	if f, ok := s.ctx.engine.formatter(s.output()); ok {
+		if _, ok := f.(rowFormatter); ok && s.started {
+			return nil
+		}
+
+		return v.printFormat(f)
+	}
+
+	if !s.started {
+		return s.startText(v)
+	}
+
+	return nil
+}
+
+// Sample sets how many rows are buffered to measure column widths before
+// text output starts.
+func (s *tableStream) Sample(rows int) {
+	s.sample = rows
+}
+
+// Truncate marks columns that may be cut short with an ellipsis to fit the
+// terminal width.
+func (s *tableStream) Truncate(columns ...int) {
+	s.truncate = append(s.truncate, columns...)
+}
+
+// Wrap marks columns that may be wrapped onto multiple lines to fit the
+// terminal width.
+func (s *tableStream) Wrap(columns ...int) {
+	s.wrap = append(s.wrap, columns...)
+}
+
+func (s *tableStream) output() string {
+	return s.ctx.Flags().String("output")
+}
+
+// view applies --filter and --columns to rows.
+func (s *tableStream) view(rows [][]any) (*tableWriter, error) {
+	if s.ctx.Flags().String("sort") != "" {
+		return nil, Errorf("--sort is not supported for this command").WithCode("invalid_sort")
+	}
+
+	t := &tableWriter{ctx: s.ctx, columns: s.columns, rows: rows, truncate: s.truncate, wrap: s.wrap}
+
+	return t.view()
+}
+
+func (s *tableStream) write(v *tableWriter, row []any) error {
+	if format := s.ctx.Flags().String("format"); format != "" {
+		return s.writeTemplate(v, row, format)
+	}
+
+	if s.output() == "json" {
+		return s.writeJSON(v, row)
+	}
+
+	if f, ok := s.ctx.engine.formatter(s.output()); ok {
+		return s.writeFormat(v, row, f)
+	}
+
+	return s.writeText(v, row)
+}
+
+func (s *tableStream) writeFormat(v *tableWriter, row []any, f Formatter) error {
+	rf, ok := f.(rowFormatter)
+
+	// formatters that can't write a row at a time get the whole table on close
+	if !ok {
+		s.rows = append(s.rows, row)
+		return nil
+	}
+
+	values, err := s.values(v, row)
+	if err != nil {
+		return err //nowrap
+	}
+
+	buf := &bytes.Buffer{}
+
+	if !s.started {
+		s.started = true
+
+		if err := rf.formatHeader(buf, v.keys()); err != nil {
+			return err //nowrap
+		}
+	}
+
+	if err := rf.formatRow(buf, v.keys(), values); err != nil {
+		return err //nowrap
+	}
+
+	return s.writeString(buf.String())
+}
+
+func (s *tableStream) writeJSON(v *tableWriter, row []any) error {
+	values, err := s.values(v, row)
+	if err != nil {
+		return err //nowrap
+	}
+
+	data, err := orderedJSON(v.keys(), values)
+	if err != nil {
+		return err //nowrap
+	}
+
+	buf := &bytes.Buffer{}
+
+	buf.WriteString(ddl.If(s.count == 0, "[\n  ", ",\n  "))
+
+	if err := json.Indent(buf, data, "  ", "  "); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return s.writeString(buf.String())
+}
+
+func (s *tableStream) writeTemplate(v *tableWriter, row []any, format string) error {
+	if s.template == nil {
+		t, err := parseTemplate(format, v.keys())
+		if err != nil {
+			return err //nowrap
+		}
+
+		s.template = t
+	}
+
+	values, err := s.values(v, row)
+	if err != nil {
+		return err //nowrap
+	}
+
+	buf := &bytes.Buffer{}
+
+	if err := executeTemplate(s.template, buf, v.keys(), templateItem(v.keys(), values)); err != nil {
+		return err //nowrap
+	}
+
+	return s.writeString(buf.String())
+}
+
+func (s *tableStream) writeText(v *tableWriter, row []any) error {
+	if s.started {
+		s.cw.printRow(row, s.limits, s.widths)
+		return nil
+	}
+
+	s.rows = append(s.rows, row)
+
+	if len(s.rows) < s.sample {
+		return nil
+	}
+
+	sample := *v
+	sample.rows = s.rows
+
+	return s.startText(&sample)
+}
+
+// startText measures the buffered rows, writes them with the header and
+// fixes the layout for the rows that follow.
+func (s *tableStream) startText(v *tableWriter) error {
+	s.started = true
+	s.cw = v.columnWriter()
+
+	for _, r := range s.rows {
+		s.cw.Append(r...)
+	}
+
+	s.limits, s.widths = s.cw.layout()
+
+	for _, r := range s.cw.rows {
+		s.cw.printRow(r, s.limits, s.widths)
+	}
+
+	s.cw.rows = nil
+	s.rows = nil
+
+	return nil
+}
+
+func (s *tableStream) values(v *tableWriter, row []any) ([]any, error) {
+	rows, err := (&tableWriter{defs: v.defs, rows: [][]any{row}}).values()
+	if err != nil {
+		return nil, err //nowrap
+	}
+
+	return rows[0], nil
+}
+
+func (s *tableStream) writeString(data string) error {
+	if _, err := s.ctx.Write([]byte(data)); err != nil {
+		return err //nowrap
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package stdcli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTableStreamText(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ctx := testContext(buf, buf, nil)
+
+	s := ctx.TableStream("ID", Column{Header: "Name", MinWidth: 8}, "Status")
+	s.Sample(2)
+
+	s.Append(1, "web", "running")
+
+	if buf.Len() != 0 {
+		t.Fatalf("output = %q, want rows buffered until the sample is full", buf.String())
+	}
+
+	s.Append(2, "worker", "stopped")
+
+	want := "ID  Name      Status\n1   web       running\n2   worker    stopped\n"
+
+	if got := stripTags(buf.String()); got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+
+	s.Append(300, "scheduler", "running")
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	want += "300  scheduler  running\n"
+
+	if got := stripTags(buf.String()); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestTableStreamTextShort(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ctx := testContext(buf, buf, nil)
+
+	s := ctx.TableStream("ID", "Name")
+	s.Append(1, "web")
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if got, want := stripTags(buf.String()), "ID  Name\n1   web\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestTableStreamJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ctx := testContext(buf, buf, map[string]string{"output": "json"})
+
+	s := ctx.TableStream("ID", "Name")
+
+	s.Append(1, "<id>web</id>")
+
+	if got, want := buf.String(), "[\n  {\n    \"id\": 1,\n    \"name\": \"web\"\n  }"; got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+
+	s.Append(2, "worker")
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	var got []map[string]any
+
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json %q: %v", buf.String(), err)
+	}
+
+	if len(got) != 2 || got[1]["name"] != "worker" {
+		t.Errorf("output = %v, want two rows", got)
+	}
+
+	buf.Reset()
+
+	empty := ctx.TableStream("ID")
+
+	if err := empty.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if buf.String() != "[]" {
+		t.Errorf("output = %q, want []", buf.String())
+	}
+}
+
+func TestTableStreamFormats(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags map[string]string
+		want  string
+	}{
+		{"jsonl", map[string]string{"output": "jsonl"}, "{\"id\":1,\"name\":\"web\"}\n{\"id\":2,\"name\":\"worker\"}\n"},
+		{"csv", map[string]string{"output": "csv"}, "id,name\n1,web\n2,worker\n"},
+		{"template", map[string]string{"format": "{{.name}}"}, "web\nworker\n"},
+		{"filter", map[string]string{"output": "csv", "filter": "name=worker"}, "id,name\n2,worker\n"},
+		{"columns", map[string]string{"output": "jsonl", "columns": "name"}, "{\"name\":\"web\"}\n{\"name\":\"worker\"}\n"},
+		{"filter everything", map[string]string{"output": "yaml", "filter": "name=none"}, "[]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			ctx := testContext(buf, buf, tt.flags)
+
+			s := ctx.TableStream("ID", "Name")
+
+			if err := s.Append(1, "web"); err != nil {
+				t.Fatalf("Append() error = %v", err)
+			}
+
+			first := buf.String()
+
+			if err := s.Append(2, "worker"); err != nil {
+				t.Fatalf("Append() error = %v", err)
+			}
+
+			if err := s.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+
+			if tt.flags["filter"] == "" && (first == "" || !strings.HasPrefix(tt.want, first)) {
+				t.Errorf("first row was not streamed: %q", first)
+			}
+		})
+	}
+}
+
+func TestTableStreamErrors(t *testing.T) {
+	ctx := testContext(&bytes.Buffer{}, &bytes.Buffer{}, map[string]string{"sort": "name"})
+
+	if err := ctx.TableStream("Name").Append("web"); err == nil || err.Error() != "--sort is not supported for this command" {
+		t.Errorf("Append() error = %v, want sort error", err)
+	}
+
+	ctx = testContext(&bytes.Buffer{}, &bytes.Buffer{}, nil)
+
+	s := ctx.TableStream("Name")
+	s.Close()
+
+	if err := s.Append("web"); err == nil {
+		t.Errorf("Append() after Close() error = nil, want error")
+	}
+}
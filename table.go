@@ -1,20 +1,54 @@
 package stdcli
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type TableWriter interface {
 	Append(row ...any)
 	Print() error
+	Truncate(columns ...int)
+	Wrap(columns ...int)
 }
 
+// Column defines a table column with a display header, the key used for
+// structured output and the type its values are converted to there. Key
+// defaults to the header in snake_case. MinWidth pads text output to at least
+// that many columns.
+type Column struct {
+	Header   string
+	Key      string
+	MinWidth int
+	Type     ColumnType
+}
+
+type ColumnType string
+
+const (
+	ColumnAny      ColumnType = ""
+	ColumnBool     ColumnType = "bool"
+	ColumnDuration ColumnType = "duration"
+	ColumnNumber   ColumnType = "number"
+	ColumnString   ColumnType = "string"
+	ColumnTime     ColumnType = "time"
+)
+
 type tableWriter struct {
-	ctx     Context
-	columns []any
-	rows    [][]any
+	ctx      *defaultContext
+	columns  []any
+	defs     []Column
+	rows     [][]any
+	truncate []int
+	wrap     []int
 }
 
 var _ TableWriter = &tableWriter{}
@@ -23,29 +57,134 @@ func (t *tableWriter) Append(row ...any) {
 	t.rows = append(t.rows, row)
 }
 
+// Truncate marks columns that may be cut short with an ellipsis to fit the
+// terminal width.
+func (t *tableWriter) Truncate(columns ...int) {
+	t.truncate = append(t.truncate, columns...)
+}
+
+// Wrap marks columns that may be wrapped onto multiple lines to fit the
+// terminal width.
+func (t *tableWriter) Wrap(columns ...int) {
+	t.wrap = append(t.wrap, columns...)
+}
+
+// Print writes the table after applying --filter, --sort and --columns, which
+// are available on commands that set CommandOptions.Table.
 func (t *tableWriter) Print() error {
-	switch t.ctx.Flags().String("output") {
-	case "json":
+	v, err := t.view()
+	if err != nil {
+		return err //nowrap
+	}
+
+	return v.print()
+}
+
+func (t *tableWriter) print() error {
+	if format := t.ctx.Flags().String("format"); format != "" {
+		return t.printTemplate(format)
+	}
+
+	output := t.ctx.Flags().String("output")
+
+	if output == "json" {
 		return t.printJSON()
-	default:
-		return t.printText()
 	}
+
+	if f, ok := t.ctx.engine.formatter(output); ok {
+		return t.printFormat(f)
+	}
+
+	return t.printText()
 }
 
-func (t *tableWriter) printJSON() error {
-	lccs := []string{}
+func (t *tableWriter) printFormat(f Formatter) error {
+	rows, err := t.values()
+	if err != nil {
+		return err //nowrap
+	}
+
+	buf := &bytes.Buffer{}
 
-	for _, c := range t.columns {
-		lccs = append(lccs, strings.ToLower(c.(string)))
+	if err := f.FormatTable(buf, t.keys(), rows); err != nil {
+		return err //nowrap
 	}
 
-	vs := []map[string]any{}
+	if _, err := t.ctx.Write(buf.Bytes()); err != nil {
+		return err //nowrap
+	}
 
-	for _, r := range t.rows {
-		v := map[string]any{}
+	return nil
+}
+
+func (t *tableWriter) printTemplate(format string) error {
+	rows, err := t.values()
+	if err != nil {
+		return err //nowrap
+	}
+
+	keys := t.keys()
+	items := make([]map[string]any, len(rows))
+
+	for i, r := range rows {
+		items[i] = templateItem(keys, r)
+	}
+
+	data, err := formatTemplate(format, keys, items)
+	if err != nil {
+		return err //nowrap
+	}
+
+	if _, err := t.ctx.Write(data); err != nil {
+		return err //nowrap
+	}
+
+	return nil
+}
+
+func (t *tableWriter) keys() []string {
+	keys := make([]string, len(t.defs))
+
+	for i, d := range t.defs {
+		keys[i] = d.Key
+	}
+
+	return keys
+}
+
+// values returns the rows converted to their column types for structured
+// output.
+func (t *tableWriter) values() ([][]any, error) {
+	rows := make([][]any, len(t.rows))
+
+	for i, r := range t.rows {
+		rows[i] = make([]any, len(t.defs))
+
+		for j, d := range t.defs {
+			v, err := d.value(rowValue(r, j))
+			if err != nil {
+				return nil, err //nowrap
+			}
 
-		for i := range t.columns {
-			v[lccs[i]] = r[i]
+			rows[i][j] = v
+		}
+	}
+
+	return rows, nil
+}
+
+func (t *tableWriter) printJSON() error {
+	rows, err := t.values()
+	if err != nil {
+		return err //nowrap
+	}
+
+	vs := []json.RawMessage{}
+
+	for _, r := range rows {
+		v, err := orderedJSON(t.keys(), r)
+		if err != nil {
+			return err //nowrap
 		}
 
 		vs = append(vs, v)
@@ -64,39 +203,375 @@ func (t *tableWriter) printJSON() error {
 }
 
 func (t *tableWriter) printText() error {
-	cw := t.ctx.Columns()
+	cw := t.columnWriter()
 
-	cs := make([]any, len(t.columns))
+	for _, r := range t.rows {
+		cw.Append(r...)
+	}
 
-	for i, c := range t.columns {
-		cs[i] = fmt.Sprintf("<h1>%s</h1>", c)
+	return cw.Print()
+}
+
+// columnWriter returns a column writer holding the header row.
+func (t *tableWriter) columnWriter() *columnWriter {
+	cw := &columnWriter{ctx: t.ctx, minWidths: make([]int, len(t.defs))}
+	cw.Truncate(t.truncate...)
+	cw.Wrap(t.wrap...)
+
+	cs := make([]any, len(t.defs))
+
+	for i, d := range t.defs {
+		cs[i] = fmt.Sprintf("<h1>%s</h1>", d.Header)
+		cw.minWidths[i] = d.MinWidth
 	}
 
 	cw.Append(cs...)
 
-	for _, r := range t.rows {
-		cw.Append(r...)
+	return cw
+}
+
+// view returns a copy of the table filtered by --filter, sorted by --sort and
+// limited to the columns in --columns.
+func (t *tableWriter) view() (*tableWriter, error) {
+	v := *t
+	v.rows = slices.Clone(t.rows)
+
+	defs, err := columnDefs(t.columns)
+	if err != nil {
+		return nil, err //nowrap
 	}
 
-	return cw.Print()
+	v.defs = defs
+
+	if err := v.filter(t.ctx.Flags().String("filter")); err != nil {
+		return nil, err //nowrap
+	}
+
+	if err := v.sort(t.ctx.Flags().String("sort")); err != nil {
+		return nil, err //nowrap
+	}
+
+	if err := v.selectColumns(t.ctx.Flags().String("columns")); err != nil {
+		return nil, err //nowrap
+	}
+
+	return &v, nil
 }
 
-func (t *tableWriter) widths() []int {
-	w := make([]int, len(t.columns))
+// column returns the index of the column with key name.
+func (t *tableWriter) column(name string) (int, error) {
+	keys := t.keys()
 
-	for i, c := range t.columns {
-		w[i] = len(stripTag(c))
+	if i := slices.Index(keys, strings.ToLower(strings.TrimSpace(name))); i >= 0 {
+		return i, nil
+	}
+
+	return 0, Errorf("unknown column: %s", name).WithCode("unknown_column").WithHint("available columns: %s", strings.Join(keys, ", "))
+}
+
+// filter keeps rows matching every comma separated key=value (equal) or
+// key~value (contains) condition in spec, ignoring case.
+func (t *tableWriter) filter(spec string) error {
+	if spec == "" {
+		return nil
+	}
+
+	for _, cond := range strings.Split(spec, ",") {
+		i := strings.IndexAny(cond, "=~")
+		if i < 1 {
+			return Errorf("invalid filter: %s", cond).WithCode("invalid_filter").WithHint("use key=value to match or key~value to search")
+		}
 
-		for _, r := range t.rows {
-			if len(r) > i {
-				if lri := len(stripTag(r[i])); lri > w[i] {
-					w[i] = lri
-				}
+		col, err := t.column(cond[:i])
+		if err != nil {
+			return err //nowrap
+		}
+
+		op, want := cond[i], strings.ToLower(cond[i+1:])
+
+		t.rows = slices.DeleteFunc(t.rows, func(r []any) bool {
+			got := strings.ToLower(visibleText(rowValue(r, col)))
+
+			if op == '~' {
+				return !strings.Contains(got, want)
 			}
+
+			return got != want
+		})
+	}
+
+	return nil
+}
+
+// sort orders rows by the comma separated columns in spec. A leading - sorts
+// a column in descending order.
+func (t *tableWriter) sort(spec string) error {
+	if spec == "" {
+		return nil
+	}
+
+	type sortKey struct {
+		col  int
+		desc bool
+	}
+
+	keys := []sortKey{}
+
+	for _, name := range strings.Split(spec, ",") {
+		desc := strings.HasPrefix(name, "-")
+
+		col, err := t.column(strings.TrimPrefix(name, "-"))
+		if err != nil {
+			return err //nowrap
 		}
+
+		keys = append(keys, sortKey{col: col, desc: desc})
 	}
 
-	w[len(w)-1] = 0
+	sort.SliceStable(t.rows, func(a, b int) bool {
+		for _, k := range keys {
+			c := compareValues(rowValue(t.rows[a], k.col), rowValue(t.rows[b], k.col))
+
+			if c != 0 {
+				return (c < 0) != k.desc
+			}
+		}
+
+		return false
+	})
+
+	return nil
+}
+
+// selectColumns limits the table to the comma separated columns in spec, in
+// that order.
+func (t *tableWriter) selectColumns(spec string) error {
+	if spec == "" {
+		return nil
+	}
+
+	cols := []int{}
+
+	for _, name := range strings.Split(spec, ",") {
+		col, err := t.column(name)
+		if err != nil {
+			return err //nowrap
+		}
+
+		cols = append(cols, col)
+	}
+
+	columns := make([]any, len(cols))
+	defs := make([]Column, len(cols))
+
+	for i, col := range cols {
+		columns[i] = t.columns[col]
+		defs[i] = t.defs[col]
+	}
+
+	rows := make([][]any, len(t.rows))
+
+	for i, r := range t.rows {
+		rows[i] = make([]any, len(cols))
+
+		for j, col := range cols {
+			rows[i][j] = rowValue(r, col)
+		}
+	}
+
+	t.columns = columns
+	t.defs = defs
+	t.rows = rows
+	t.truncate = remapColumns(t.truncate, cols)
+	t.wrap = remapColumns(t.wrap, cols)
+
+	return nil
+}
+
+// columnDefs normalizes the columns passed to Context.Table, which can be
+// header strings or Column definitions.
+func columnDefs(columns []any) ([]Column, error) {
+	defs := make([]Column, len(columns))
+
+	for i, c := range columns {
+		switch t := c.(type) {
+		case string:
+			defs[i] = Column{Header: t}
+		case Column:
+			defs[i] = t
+		case *Column:
+			defs[i] = *t
+		default:
+			return nil, Errorf("invalid column %d: %T", i, c).WithCode("invalid_column").WithHint("use a string or a stdcli.Column")
+		}
+
+		if defs[i].Key == "" {
+			defs[i].Key = snakeCase(defs[i].Header)
+		}
+	}
+
+	return defs, nil
+}
+
+// value converts v to the column type, stripping tags from text.
+func (c Column) value(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	switch c.Type {
+	case ColumnBool:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+
+		b, err := strconv.ParseBool(visibleText(v))
+		if err != nil {
+			return nil, c.typeError(v)
+		}
+
+		return b, nil
+	case ColumnDuration:
+		if d, ok := v.(time.Duration); ok {
+			return d.String(), nil
+		}
+
+		d, err := time.ParseDuration(visibleText(v))
+		if err != nil {
+			return nil, c.typeError(v)
+		}
+
+		return d.String(), nil
+	case ColumnNumber:
+		n, ok := numberValue(v)
+		if !ok {
+			return nil, c.typeError(v)
+		}
+
+		if n == float64(int64(n)) {
+			return int64(n), nil
+		}
+
+		return n, nil
+	case ColumnString:
+		return visibleText(v), nil
+	case ColumnTime:
+		t, ok := timeValue(v)
+		if !ok {
+			return nil, c.typeError(v)
+		}
+
+		return t, nil
+	default:
+		return plainValue(v), nil
+	}
+}
+
+func (c Column) typeError(v any) error {
+	return Errorf("column %s: %q is not a %s", c.Key, visibleText(v), c.Type).WithCode("invalid_value")
+}
+
+var snakeCaser = regexp.MustCompile(`[^a-z0-9]+`)
+
+// snakeCase lowercases s and joins its words with underscores.
+func snakeCase(s string) string {
+	return strings.Trim(snakeCaser.ReplaceAllString(strings.ToLower(visibleText(s)), "_"), "_")
+}
+
+func rowValue(r []any, i int) any {
+	if i < len(r) {
+		return r[i]
+	}
+
+	return nil
+}
+
+// remapColumns translates column indexes after the columns in cols have been
+// selected.
+func remapColumns(marks, cols []int) []int {
+	remapped := []int{}
+
+	for i, col := range cols {
+		if slices.Contains(marks, col) {
+			remapped = append(remapped, i)
+		}
+	}
+
+	return remapped
+}
+
+// compareValues orders numbers, durations and times by value and everything
+// else as text. Strings that parse as one of those are compared as such.
+func compareValues(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	if at, ok := timeValue(a); ok {
+		if bt, ok := timeValue(b); ok {
+			return at.Compare(bt)
+		}
+	}
+
+	if an, ok := numberValue(a); ok {
+		if bn, ok := numberValue(b); ok {
+			switch {
+			case an < bn:
+				return -1
+			case an > bn:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	return strings.Compare(strings.ToLower(visibleText(a)), strings.ToLower(visibleText(b)))
+}
+
+func numberValue(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.String:
+		s := visibleText(v)
+
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, true
+		}
+
+		if d, err := time.ParseDuration(s); err == nil {
+			return float64(d), true
+		}
+	}
+
+	return 0, false
+}
+
+func timeValue(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case *time.Time:
+		if t != nil {
+			return *t, true
+		}
+	case string:
+		if pt, err := time.Parse(time.RFC3339, visibleText(t)); err == nil {
+			return pt, true
+		}
+	}
 
-	return w
+	return time.Time{}, false
 }
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestTableWriterText(t *testing.T) {
@@ -197,49 +198,6 @@ func TestTableWriterJSON(t *testing.T) {
 	}
 }
 
-func TestTableWriterWidths(t *testing.T) {
-	buf := &bytes.Buffer{}
-	writer := &Writer{
-		Stdout: buf,
-		Stderr: buf,
-		Color:  false,
-		Tags:   map[string]Renderer{},
-	}
-
-	ctx := &defaultContext{
-		Context: context.Background(),
-		engine: &Engine{
-			Writer: writer,
-		},
-	}
-
-	tw := &tableWriter{
-		ctx:     ctx,
-		columns: []any{"Short", "MediumLength", "VeryLongColumnName"},
-		rows: [][]any{
-			{"a", "bb", "ccc"},
-			{"dddd", "e", "f"},
-		},
-	}
-
-	widths := tw.widths()
-
-	// First column: max(5 (Short), 1 (a), 4 (dddd)) = 5
-	// Second column: max(12 (MediumLength), 2 (bb), 1 (e)) = 12
-	// Third column: 0 (last column not padded)
-	expectedWidths := []int{5, 12, 0}
-
-	if len(widths) != len(expectedWidths) {
-		t.Fatalf("expected %d widths, got %d", len(expectedWidths), len(widths))
-	}
-
-	for i, want := range expectedWidths {
-		if widths[i] != want {
-			t.Errorf("widths[%d] = %d, want %d", i, widths[i], want)
-		}
-	}
-}
-
 func TestTableWriterColumnCaseLowering(t *testing.T) {
 	buf := &bytes.Buffer{}
 	writer := &Writer{
@@ -302,3 +260,305 @@ func getKeys(m map[string]any) []string {
 	}
 	return keys
 }
+
+func TestTableWriterView(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		flags   map[string]string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "no flags",
+			flags: map[string]string{},
+			want:  "web,running,10,2m0s\nworker,stopped,9,1h0m0s\ncron,running,100,30s\n",
+		},
+		{
+			name:  "sort numbers",
+			flags: map[string]string{"sort": "count"},
+			want:  "worker,stopped,9,1h0m0s\nweb,running,10,2m0s\ncron,running,100,30s\n",
+		},
+		{
+			name:  "sort descending",
+			flags: map[string]string{"sort": "-count"},
+			want:  "cron,running,100,30s\nweb,running,10,2m0s\nworker,stopped,9,1h0m0s\n",
+		},
+		{
+			name:  "sort durations",
+			flags: map[string]string{"sort": "age"},
+			want:  "cron,running,100,30s\nweb,running,10,2m0s\nworker,stopped,9,1h0m0s\n",
+		},
+		{
+			name:  "sort times",
+			flags: map[string]string{"sort": "created"},
+			want:  "worker,stopped,9,1h0m0s\nweb,running,10,2m0s\ncron,running,100,30s\n",
+		},
+		{
+			name:  "sort multiple",
+			flags: map[string]string{"sort": "status,-name"},
+			want:  "web,running,10,2m0s\ncron,running,100,30s\nworker,stopped,9,1h0m0s\n",
+		},
+		{
+			name:  "filter equal",
+			flags: map[string]string{"filter": "status=running"},
+			want:  "web,running,10,2m0s\ncron,running,100,30s\n",
+		},
+		{
+			name:  "filter contains",
+			flags: map[string]string{"filter": "name~R"},
+			want:  "worker,stopped,9,1h0m0s\ncron,running,100,30s\n",
+		},
+		{
+			name:  "filter multiple",
+			flags: map[string]string{"filter": "name~r,status=running"},
+			want:  "cron,running,100,30s\n",
+		},
+		{
+			name:  "columns",
+			flags: map[string]string{"columns": "age,name"},
+			want:  "2m0s,web\n1h0m0s,worker\n30s,cron\n",
+		},
+		{
+			name:    "unknown column",
+			flags:   map[string]string{"sort": "size"},
+			wantErr: "unknown column: size",
+		},
+		{
+			name:    "invalid filter",
+			flags:   map[string]string{"filter": "running"},
+			wantErr: "invalid filter: running",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+
+			flags := Flags{}
+
+			for _, name := range []string{"columns", "filter", "format", "sort"} {
+				f := StringFlag(name, "", "")
+				f.Value = tt.flags[name]
+				flags = append(flags, &f)
+			}
+
+			flags[2].Value = `{{.name}},{{.status}},{{.count}},{{.age}}`
+
+			if cols, ok := tt.flags["columns"]; ok {
+				parts := []string{}
+				for _, c := range strings.Split(cols, ",") {
+					parts = append(parts, "{{."+c+"}}")
+				}
+				flags[2].Value = strings.Join(parts, ",")
+			}
+
+			ctx := &defaultContext{
+				Context: context.Background(),
+				flags:   flags,
+				engine:  &Engine{Writer: &Writer{Stdout: buf, Stderr: buf, Tags: DefaultWriter.Tags}},
+			}
+
+			table := ctx.Table("Name", "<h1>Status</h1>", "Count", "Age", "Created")
+			table.Append("web", "<ok>running</ok>", 10, 2*time.Minute, now.Add(-time.Hour))
+			table.Append("worker", "stopped", "9", "1h0m0s", now.Add(-2*time.Hour).Format(time.RFC3339))
+			table.Append("cron", "running", 100, "30s", now)
+
+			err := table.Print()
+
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Print() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Print() error = %v", err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableWriterColumnsJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	columnsFlag := StringFlag("columns", "", "")
+	columnsFlag.Value = "name"
+
+	outputFlag := StringFlag("output", "", "")
+	outputFlag.Value = "json"
+
+	ctx := &defaultContext{
+		Context: context.Background(),
+		flags:   Flags{&columnsFlag, &outputFlag},
+		engine:  &Engine{Writer: &Writer{Stdout: buf, Stderr: buf, Tags: map[string]Renderer{}}},
+	}
+
+	table := ctx.Table("ID", "Name")
+	table.Append(1, "web")
+
+	if err := table.Print(); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+
+	var got []map[string]any
+
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal JSON: %v", err)
+	}
+
+	if len(got) != 1 || len(got[0]) != 1 || got[0]["name"] != "web" {
+		t.Errorf("output = %v, want only name", got)
+	}
+}
+
+func TestTableWriterColumnDefinitions(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	outputFlag := StringFlag("output", "", "output format")
+	outputFlag.Value = "json"
+
+	ctx := &defaultContext{
+		Context: context.Background(),
+		flags:   Flags{&outputFlag},
+		engine:  &Engine{Writer: &Writer{Stdout: buf, Stderr: buf, Tags: map[string]Renderer{}}},
+	}
+
+	table := ctx.Table(
+		"<h1>App ID</h1>",
+		Column{Header: "Created At", Type: ColumnTime},
+		Column{Header: "Count", Key: "n", Type: ColumnNumber},
+		&Column{Header: "Healthy", Type: ColumnBool},
+		Column{Header: "Uptime", Type: ColumnDuration},
+		Column{Header: "Version", Type: ColumnString},
+	)
+	table.Append("<id>app-1</id>", "2024-01-02T03:04:05Z", "42", "true", 90*time.Second, 2)
+	table.Append("app-2")
+
+	if err := table.Print(); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+
+	want := `[
+  {
+    "app_id": "app-1",
+    "created_at": "2024-01-02T03:04:05Z",
+    "n": 42,
+    "healthy": true,
+    "uptime": "1m30s",
+    "version": "2"
+  },
+  {
+    "app_id": "app-2",
+    "created_at": null,
+    "n": null,
+    "healthy": null,
+    "uptime": null,
+    "version": null
+  }
+]`
+
+	if got := buf.String(); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTableWriterColumnErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []any
+		row     []any
+		wantErr string
+	}{
+		{"invalid column", []any{"ID", 42}, []any{1, 2}, "invalid column 1: int"},
+		{"invalid number", []any{Column{Header: "Count", Type: ColumnNumber}}, []any{"many"}, `column count: "many" is not a number`},
+		{"invalid bool", []any{Column{Header: "On", Type: ColumnBool}}, []any{"maybe"}, `column on: "maybe" is not a bool`},
+		{"invalid time", []any{Column{Header: "At", Type: ColumnTime}}, []any{"today"}, `column at: "today" is not a time`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+
+			outputFlag := StringFlag("output", "", "output format")
+			outputFlag.Value = "json"
+
+			ctx := &defaultContext{
+				Context: context.Background(),
+				flags:   Flags{&outputFlag},
+				engine:  &Engine{Writer: &Writer{Stdout: buf, Stderr: buf, Tags: map[string]Renderer{}}},
+			}
+
+			table := ctx.Table(tt.columns...)
+			table.Append(tt.row...)
+
+			if err := table.Print(); err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Print() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTableFlagsOptIn(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	e := &Engine{Name: "testapp", Writer: &Writer{Stdout: buf, Stderr: buf, Tags: map[string]Renderer{}}}
+
+	var sort string
+
+	handler := func(ctx Context) error {
+		sort = ctx.Flags().String("sort")
+		return nil
+	}
+
+	e.Command("list", "list things", handler, CommandOptions{Table: true})
+	e.Command("show", "show a thing", handler, CommandOptions{})
+
+	for _, name := range []string{"columns", "filter", "sort"} {
+		if flags := e.globalFlags(&e.Commands[0]); !hasFlag(flags, name) {
+			t.Errorf("%s flag missing for opted in command", name)
+		}
+
+		if flags := e.globalFlags(&e.Commands[1]); hasFlag(flags, name) {
+			t.Errorf("%s flag added to command that did not opt in", name)
+		}
+	}
+
+	if code := e.ExecuteContext(context.Background(), []string{"list", "--sort", "name"}); code != 0 {
+		t.Errorf("exit code = %d, want 0: %s", code, buf.String())
+	}
+
+	if sort != "name" {
+		t.Errorf("sort = %q, want %q", sort, "name")
+	}
+
+	if code := e.ExecuteContext(context.Background(), []string{"show", "--sort", "name"}); code == 0 {
+		t.Errorf("exit code = 0, want unknown flag error")
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"ID", "id"},
+		{"Created At", "created_at"},
+		{"UserName", "username"},
+		{"  CPU (%)  ", "cpu"},
+		{"Last-Seen", "last_seen"},
+		{"<h1>App ID</h1>", "app_id"},
+	}
+
+	for _, tt := range tests {
+		if got := snakeCase(tt.s); got != tt.want {
+			t.Errorf("snakeCase(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package stdcli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.ddollar.dev/errors"
+)
+
+// Telemetry receives an event for every command execution. The library never
+// sends events anywhere itself; where they go is up to the implementation.
+type Telemetry interface {
+	Record(ctx context.Context, event TelemetryEvent) error
+}
+
+// TelemetryEvent describes a single command execution. Flag values and
+// arguments are deliberately left out as they may contain sensitive data.
+type TelemetryEvent struct {
+	Command  string        `json:"command"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+	ExitCode int           `json:"exit_code"`
+	Flags    []string      `json:"flags"`
+	Time     time.Time     `json:"time"`
+	Version  string        `json:"version"`
+}
+
+type fileTelemetry struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// FileTelemetry appends events as JSON lines to telemetry.jsonl in dir,
+// usually the engine's Settings directory.
+func FileTelemetry(dir string) Telemetry {
+	return &fileTelemetry{dir: dir}
+}
+
+func (t *fileTelemetry) Record(ctx context.Context, event TelemetryEvent) error {
+	if t.dir == "" {
+		return errors.Errorf("no telemetry directory")
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := os.MkdirAll(t.dir, 0700); err != nil {
+		return errors.Wrap(err)
+	}
+
+	fd, err := os.OpenFile(filepath.Join(t.dir, "telemetry.jsonl"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	defer fd.Close()
+
+	if _, err := fd.Write(append(data, '\n')); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (e *Engine) record(ctx context.Context, cmd *Command, cc *defaultContext, start time.Time, code int, err error) {
+	if e.Telemetry == nil || telemetryDisabled() {
+		return
+	}
+
+	event := TelemetryEvent{
+		Command:  strings.Join(cmd.Command, " "),
+		Duration: time.Since(start),
+		Error:    errorClass(err),
+		ExitCode: code,
+		Flags:    []string{},
+		Time:     start.UTC(),
+		Version:  e.Version,
+	}
+
+	for _, f := range cc.flags {
+		if f.Value != nil {
+			event.Flags = append(event.Flags, f.Name)
+		}
+	}
+
+	e.Telemetry.Record(ctx, event) //nolint:errcheck
+}
+
+func errorClass(err error) string {
+	switch t := errors.Cause(err).(type) {
+	case nil:
+		return ""
+	case *Error:
+		if t.Code != "" {
+			return t.Code
+		}
+		return "error"
+	case ExitCoder:
+		return "exit"
+	default:
+		return fmt.Sprintf("%T", t)
+	}
+}
+
+// telemetryDisabled honors the DO_NOT_TRACK convention for opting out.
+func telemetryDisabled() bool {
+	switch strings.ToLower(os.Getenv("DO_NOT_TRACK")) {
+	case "", "0", "false":
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,155 @@
+package stdcli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.ddollar.dev/errors"
+)
+
+type testTelemetry struct {
+	events []TelemetryEvent
+}
+
+func (t *testTelemetry) Record(ctx context.Context, event TelemetryEvent) error {
+	t.events = append(t.events, event)
+	return nil
+}
+
+func telemetryEngine(tt Telemetry) *Engine {
+	buf := &bytes.Buffer{}
+
+	e := testEngine(buf, buf)
+	e.Telemetry = tt
+
+	e.Command("apps create", "create an app", func(ctx Context) error {
+		if ctx.Flags().Bool("fail") {
+			return Errorf("failed").WithCode("create_failed").WithExit(3)
+		}
+		return nil
+	}, CommandOptions{
+		Flags: []Flag{
+			BoolFlag("fail", "", "fail"),
+			StringFlag("region", "r", "region"),
+			StringFlag("token", "", "token"),
+		},
+	})
+
+	e.Command("broken", "return a plain error", func(ctx Context) error {
+		return errors.Wrap(os.ErrNotExist)
+	}, CommandOptions{})
+
+	return e
+}
+
+func TestTelemetryRecord(t *testing.T) {
+	tt := &testTelemetry{}
+	e := telemetryEngine(tt)
+
+	e.ExecuteContext(context.Background(), []string{"apps", "create", "-r", "us-east", "--token", "secret"})
+	e.ExecuteContext(context.Background(), []string{"apps", "create", "--fail"})
+	e.ExecuteContext(context.Background(), []string{"broken"})
+
+	if len(tt.events) != 3 {
+		t.Fatalf("got %d events, want 3", len(tt.events))
+	}
+
+	tests := []struct {
+		command  string
+		error    string
+		exitCode int
+		flags    []string
+	}{
+		{"apps create", "", 0, []string{"region", "token"}},
+		{"apps create", "create_failed", 3, []string{"fail"}},
+		{"broken", "*errors.errorString", 1, []string{}},
+	}
+
+	for i, want := range tests {
+		got := tt.events[i]
+
+		if got.Command != want.command {
+			t.Errorf("event %d command = %q, want %q", i, got.Command, want.command)
+		}
+
+		if got.Error != want.error {
+			t.Errorf("event %d error = %q, want %q", i, got.Error, want.error)
+		}
+
+		if got.ExitCode != want.exitCode {
+			t.Errorf("event %d exit code = %d, want %d", i, got.ExitCode, want.exitCode)
+		}
+
+		if !reflect.DeepEqual(got.Flags, want.flags) {
+			t.Errorf("event %d flags = %v, want %v", i, got.Flags, want.flags)
+		}
+
+		if got.Version != "1.0.0" {
+			t.Errorf("event %d version = %q, want %q", i, got.Version, "1.0.0")
+		}
+
+		if got.Time.IsZero() {
+			t.Errorf("event %d has no time", i)
+		}
+	}
+}
+
+func TestTelemetryDoNotTrack(t *testing.T) {
+	t.Setenv("DO_NOT_TRACK", "1")
+
+	tt := &testTelemetry{}
+	e := telemetryEngine(tt)
+
+	e.ExecuteContext(context.Background(), []string{"apps", "create"})
+
+	if len(tt.events) != 0 {
+		t.Errorf("got %d events, want 0", len(tt.events))
+	}
+}
+
+func TestFileTelemetry(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "settings")
+
+	e := telemetryEngine(FileTelemetry(dir))
+
+	e.ExecuteContext(context.Background(), []string{"apps", "create"})
+	e.ExecuteContext(context.Background(), []string{"apps", "create", "--fail"})
+
+	fd, err := os.Open(filepath.Join(dir, "telemetry.jsonl"))
+	if err != nil {
+		t.Fatalf("failed to open telemetry file: %v", err)
+	}
+	defer fd.Close()
+
+	events := []TelemetryEvent{}
+
+	s := bufio.NewScanner(fd)
+
+	for s.Scan() {
+		var ev TelemetryEvent
+		if err := json.Unmarshal(s.Bytes(), &ev); err != nil {
+			t.Fatalf("invalid telemetry line %q: %v", s.Text(), err)
+		}
+		events = append(events, ev)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+
+	if events[1].Command != "apps create" || events[1].ExitCode != 3 {
+		t.Errorf("event = %+v", events[1])
+	}
+}
+
+func TestFileTelemetryNoDirectory(t *testing.T) {
+	if err := FileTelemetry("").Record(context.Background(), TelemetryEvent{}); err == nil {
+		t.Errorf("Record() error = nil, want error")
+	}
+}
@@ -0,0 +1,190 @@
+package stdcli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"go.ddollar.dev/errors"
+)
+
+// templateFuncs are available to --format templates along with the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	"join":     templateJoin,
+	"json":     templateJSON,
+	"timeago":  templateTimeago,
+	"truncate": templateTruncate,
+	"upper":    strings.ToUpper,
+}
+
+var templateEscaper = strings.NewReplacer(`\t`, "\t", `\n`, "\n")
+
+// formatTemplate renders each item through the Go template in format, one
+// item per line. Items are keyed by the same lowercase names as the json
+// output.
+func formatTemplate(format string, keys []string, items []map[string]any) ([]byte, error) {
+	t, err := parseTemplate(format, keys)
+	if err != nil {
+		return nil, err //nowrap
+	}
+
+	buf := &bytes.Buffer{}
+
+	for _, item := range items {
+		if err := executeTemplate(t, buf, keys, item); err != nil {
+			return nil, err //nowrap
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func parseTemplate(format string, keys []string) (*template.Template, error) {
+	t, err := template.New("--format").Funcs(templateFuncs).Option("missingkey=error").Parse(templateEscaper.Replace(format))
+	if err != nil {
+		return nil, templateError(err, keys)
+	}
+
+	return t, nil
+}
+
+// executeTemplate renders item through t followed by a newline.
+func executeTemplate(t *template.Template, w io.Writer, keys []string, item map[string]any) error {
+	buf := &bytes.Buffer{}
+
+	if err := t.Execute(buf, item); err != nil {
+		return templateError(err, keys)
+	}
+
+	buf.WriteString("\n")
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func templateError(err error, keys []string) error {
+	e := Errorf("invalid format: %s", strings.TrimPrefix(err.Error(), "template: ")).WithCode("invalid_format")
+
+	if len(keys) > 0 {
+		e = e.WithHint("available keys: %s", strings.Join(keys, ", "))
+	}
+
+	return e
+}
+
+func templateItem(keys []string, values []any) map[string]any {
+	item := map[string]any{}
+
+	for i, k := range keys {
+		item[k] = nil
+
+		if i < len(values) {
+			item[k] = values[i]
+		}
+	}
+
+	return item
+}
+
+func templateJoin(v any, sep string) string {
+	if v == nil {
+		return ""
+	}
+
+	rv := reflect.ValueOf(v)
+
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return fmt.Sprintf("%v", v)
+	}
+
+	parts := make([]string, rv.Len())
+
+	for i := range parts {
+		parts[i] = fmt.Sprintf("%v", rv.Index(i).Interface())
+	}
+
+	return strings.Join(parts, sep)
+}
+
+func templateJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+
+	return string(data), nil
+}
+
+func templateTimeago(v any) (string, error) {
+	var t time.Time
+
+	switch tv := v.(type) {
+	case time.Time:
+		t = tv
+	case *time.Time:
+		if tv == nil {
+			return "", nil
+		}
+		t = *tv
+	case int:
+		t = time.Unix(int64(tv), 0)
+	case int64:
+		t = time.Unix(tv, 0)
+	case string:
+		pt, err := time.Parse(time.RFC3339, tv)
+		if err != nil {
+			return "", errors.Errorf("timeago: %q is not an RFC3339 time", tv)
+		}
+		t = pt
+	default:
+		return "", errors.Errorf("timeago: unsupported type %T", v)
+	}
+
+	return timeago(time.Since(t)), nil
+}
+
+// timeago describes d as a rough relative time such as "3 minutes ago".
+func timeago(d time.Duration) string {
+	suffix := "ago"
+
+	if d < 0 {
+		d = -d
+		suffix = "from now"
+	}
+
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+		{"second", time.Second},
+	}
+
+	for _, u := range units {
+		if n := int(d / u.size); n > 0 {
+			if n > 1 {
+				return fmt.Sprintf("%d %ss %s", n, u.name, suffix)
+			}
+			return fmt.Sprintf("1 %s %s", u.name, suffix)
+		}
+	}
+
+	return "just now"
+}
+
+func templateTruncate(n int, v any) string {
+	return truncateText(fmt.Sprintf("%v", v), n)
+}
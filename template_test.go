@@ -0,0 +1,118 @@
+package stdcli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTableWriterTemplate(t *testing.T) {
+	created := time.Now().Add(-3 * time.Hour)
+
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr string
+	}{
+		{"fields", `{{.id}}\t{{.status}}`, "1\trunning\n2\tstopped\n", ""},
+		{"upper", `{{upper .name}}`, "WEB\nWORKER\n", ""},
+		{"join", `{{join .tags ","}}`, "a,b\n\n", ""},
+		{"json", `{{json .tags}}`, "[\"a\",\"b\"]\nnull\n", ""},
+		{"truncate", `{{truncate 4 .name}}`, "web\nwor…\n", ""},
+		{"timeago", `{{timeago .created}}`, "3 hours ago\n3 hours ago\n", ""},
+		{"bad template", `{{.id`, "", "invalid format: --format:1: unclosed action"},
+		{"unknown function", `{{lower .id}}`, "", `invalid format: --format:1: function "lower" not defined`},
+		{"unknown key", `{{.missing}}`, "", "invalid format: --format:1:2: executing \"--format\" at <.missing>: map has no entry for key \"missing\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			ctx := testContext(buf, buf, map[string]string{"format": tt.format, "output": "json"})
+
+			table := ctx.Table("ID", "<h1>Name</h1>", "Status", "Tags", "Created")
+			table.Append(1, "<id>web</id>", "running", []string{"a", "b"}, created)
+			table.Append(2, "worker", "stopped", nil, created)
+
+			err := table.Print()
+
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Print() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Print() error = %v", err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateErrorHint(t *testing.T) {
+	ctx := testContext(&bytes.Buffer{}, &bytes.Buffer{}, map[string]string{"format": `{{.nope}}`, "output": "json"})
+
+	table := ctx.Table("ID", "Name")
+	table.Append(1, "web")
+
+	err := table.Print()
+
+	e, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("Print() error = %T, want *Error", err)
+	}
+
+	if len(e.Hints) != 1 || e.Hints[0] != "available keys: id, name" {
+		t.Errorf("hints = %v, want available keys", e.Hints)
+	}
+}
+
+func TestInfoWriterTemplate(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ctx := testContext(buf, buf, map[string]string{"format": `{{.name}} is {{.status}}`, "output": "json"})
+
+	info := ctx.Info()
+	info.Add("Name", "web")
+	info.Add("Status", "<ok>running</ok>")
+
+	if err := info.Print(); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+
+	if got, want := buf.String(), "web is running\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestTimeago(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "just now"},
+		{500 * time.Millisecond, "just now"},
+		{time.Second, "1 second ago"},
+		{45 * time.Second, "45 seconds ago"},
+		{90 * time.Minute, "1 hour ago"},
+		{49 * time.Hour, "2 days ago"},
+		{400 * 24 * time.Hour, "1 year ago"},
+		{-5 * time.Minute, "5 minutes from now"},
+	}
+
+	for _, tt := range tests {
+		if got := timeago(tt.d); got != tt.want {
+			t.Errorf("timeago(%s) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+
+	if _, err := templateTimeago("yesterday"); err == nil || !strings.Contains(err.Error(), "RFC3339") {
+		t.Errorf("templateTimeago() error = %v, want RFC3339 error", err)
+	}
+}
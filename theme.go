@@ -0,0 +1,424 @@
+package stdcli
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Color is a terminal color: an xterm-256 index like "214" or a hex RGB value
+// like "#ff8700". Colors are downgraded to what the terminal supports.
+type Color string
+
+// Style is how a tag is rendered.
+type Style struct {
+	Background Color
+	Bold       bool
+	Foreground Color
+	Italic     bool
+	Underline  bool
+}
+
+// Theme maps tag names to styles. The "error-label" style is used for the
+// "ERROR:" prefix of the error tag.
+type Theme map[string]Style
+
+// ColorDepth is the number of colors a terminal supports. The zero value
+// supports all colors.
+type ColorDepth int
+
+const (
+	ColorDepthTrue ColorDepth = iota
+	ColorDepth256
+	ColorDepth16
+)
+
+// Themes are the named palettes available to SetTheme. The dark theme is
+// the default unless COLORFGBG reports a light background.
+var Themes = map[string]Theme{
+	"dark": {
+		"debug":       {Foreground: "242"},
+		"diff-add":    {Foreground: "40"},
+		"diff-delete": {Foreground: "160"},
+		"diff-hunk":   {Foreground: "38"},
+		"error":       {Foreground: "203"},
+		"error-label": {Foreground: "124"},
+		"header":      {Foreground: "242"},
+		"h1":          {Foreground: "244"},
+		"h2":          {Foreground: "241"},
+		"id":          {Foreground: "247"},
+		"info":        {Foreground: "247"},
+		"ok":          {Foreground: "46"},
+		"start":       {Foreground: "247"},
+		"u":           {Underline: true},
+		"value":       {Foreground: "251"},
+		"warning":     {Foreground: "214"},
+	},
+	"light": {
+		"debug":       {Foreground: "245"},
+		"diff-add":    {Foreground: "28"},
+		"diff-delete": {Foreground: "124"},
+		"diff-hunk":   {Foreground: "25"},
+		"error":       {Foreground: "160"},
+		"error-label": {Foreground: "124", Bold: true},
+		"header":      {Foreground: "243"},
+		"h1":          {Foreground: "240"},
+		"h2":          {Foreground: "243"},
+		"id":          {Foreground: "238"},
+		"info":        {Foreground: "238"},
+		"ok":          {Foreground: "28"},
+		"start":       {Foreground: "238"},
+		"u":           {Underline: true},
+		"value":       {Foreground: "235"},
+		"warning":     {Foreground: "166"},
+	},
+}
+
+// SetTheme replaces the renderers for the tags in t, keeping any other tags.
+func (w *Writer) SetTheme(t Theme) {
+	tags := map[string]Renderer{}
+
+	for k, v := range w.Tags {
+		tags[k] = v
+	}
+
+	for k, v := range t.Tags() {
+		tags[k] = v
+	}
+
+	w.Tags = tags
+}
+
+// Tags returns a renderer for each style in the theme.
+func (t Theme) Tags() map[string]Renderer {
+	tags := map[string]Renderer{}
+
+	for k, s := range t {
+		if k != "error-label" {
+			tags[k] = s.Renderer()
+		}
+	}
+
+	if s, ok := t["error"]; ok {
+		prefix := t["error-label"].open() + "ERROR: " + s.open()
+
+		tags["error"] = func(v string) string {
+			return prefix + stripTag(v) + "\033[0m"
+		}
+	}
+
+	return tags
+}
+
+// Renderer returns a renderer that wraps a tag's contents in the style.
+func (s Style) Renderer() Renderer {
+	open, close := s.open(), s.close()
+
+	return func(v string) string {
+		return open + stripTag(v) + close
+	}
+}
+
+func (s Style) open() string {
+	codes := ""
+
+	if s.Bold {
+		codes += "\033[1m"
+	}
+
+	if s.Italic {
+		codes += "\033[3m"
+	}
+
+	if s.Underline {
+		codes += "\033[4m"
+	}
+
+	if c := s.Foreground.code(38); c != "" {
+		codes += fmt.Sprintf("\033[%sm", c)
+	}
+
+	if c := s.Background.code(48); c != "" {
+		codes += fmt.Sprintf("\033[%sm", c)
+	}
+
+	return codes
+}
+
+// close resets only the attributes of a style without colors, so it can be
+// nested inside a colored tag.
+func (s Style) close() string {
+	if s.Foreground.code(38) != "" || s.Background.code(48) != "" {
+		return "\033[0m"
+	}
+
+	codes := ""
+
+	if s.Bold {
+		codes += "\033[22m"
+	}
+
+	if s.Italic {
+		codes += "\033[23m"
+	}
+
+	if s.Underline {
+		codes += "\033[24m"
+	}
+
+	return codes
+}
+
+// code returns the SGR parameters for the color with base 38 for foreground
+// or 48 for background.
+func (c Color) code(base int) string {
+	if r, g, b, ok := c.rgb(); ok {
+		return fmt.Sprintf("%d;2;%d;%d;%d", base, r, g, b)
+	}
+
+	if n, err := strconv.Atoi(string(c)); err == nil && n >= 0 && n < 256 {
+		return fmt.Sprintf("%d;5;%d", base, n)
+	}
+
+	return ""
+}
+
+func (c Color) rgb() (int, int, int, bool) {
+	s := string(c)
+
+	if len(s) != 7 || s[0] != '#' {
+		return 0, 0, 0, false
+	}
+
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+
+	return int(v >> 16 & 0xff), int(v >> 8 & 0xff), int(v & 0xff), true
+}
+
+// detectTheme returns the light theme if COLORFGBG reports a light
+// background and the dark theme otherwise.
+func detectTheme() Theme {
+	parts := strings.Split(os.Getenv("COLORFGBG"), ";")
+
+	if bg, err := strconv.Atoi(parts[len(parts)-1]); err == nil && (bg == 7 || bg == 15) {
+		return Themes["light"]
+	}
+
+	return Themes["dark"]
+}
+
+// envColor reports whether NO_COLOR, CLICOLOR_FORCE or FORCE_COLOR turn color
+// off or on. NO_COLOR takes precedence.
+func envColor() (bool, bool) {
+	if os.Getenv("NO_COLOR") != "" {
+		return false, true
+	}
+
+	for _, name := range []string{"CLICOLOR_FORCE", "FORCE_COLOR"} {
+		if v, ok := os.LookupEnv(name); ok {
+			return v != "0" && v != "false", true
+		}
+	}
+
+	return false, false
+}
+
+// envColorDepth detects color support from FORCE_COLOR levels, COLORTERM and
+// TERM.
+func envColorDepth() ColorDepth {
+	switch os.Getenv("FORCE_COLOR") {
+	case "1":
+		return ColorDepth16
+	case "2":
+		return ColorDepth256
+	case "3":
+		return ColorDepthTrue
+	}
+
+	switch os.Getenv("COLORTERM") {
+	case "truecolor", "24bit":
+		return ColorDepthTrue
+	}
+
+	term := os.Getenv("TERM")
+
+	switch {
+	case term == "" || strings.Contains(term, "256color"):
+		return ColorDepth256
+	case strings.Contains(term, "truecolor") || strings.Contains(term, "direct"):
+		return ColorDepthTrue
+	default:
+		return ColorDepth16
+	}
+}
+
+// applyColor turns color on or off for the --color flag and returns a
+// function that restores the previous setting.
+func (w *Writer) applyColor(mode string) (func(), error) {
+	color := w.Color
+	restore := func() { w.Color = color }
+
+	switch mode {
+	case "", "auto":
+		if v, ok := envColor(); ok {
+			w.Color = v
+		}
+	case "always":
+		w.Color = true
+	case "never":
+		w.Color = false
+	default:
+		return restore, Errorf("invalid color mode: %s", mode).WithCode("invalid_color").WithHint("use auto, always or never")
+	}
+
+	return restore, nil
+}
+
+var sgrMatcher = regexp.MustCompile("\033\\[([0-9;]*)m")
+
+// downgrade rewrites 256 and true colors in s to fit the writer's depth.
+func (w *Writer) downgrade(s string) string {
+	if w.Depth == ColorDepthTrue {
+		return s
+	}
+
+	return sgrMatcher.ReplaceAllStringFunc(s, func(seq string) string {
+		params := strings.Split(sgrMatcher.FindStringSubmatch(seq)[1], ";")
+		out := []string{}
+
+		for i := 0; i < len(params); i++ {
+			p := params[i]
+
+			if (p != "38" && p != "48") || i+1 >= len(params) {
+				out = append(out, p)
+				continue
+			}
+
+			base, _ := strconv.Atoi(p)
+			r, g, b, n := 0, 0, 0, -1
+
+			switch {
+			case params[i+1] == "5" && i+2 < len(params):
+				n, _ = strconv.Atoi(params[i+2])
+				r, g, b = xtermRGB(n)
+				i += 2
+			case params[i+1] == "2" && i+4 < len(params):
+				r, _ = strconv.Atoi(params[i+2])
+				g, _ = strconv.Atoi(params[i+3])
+				b, _ = strconv.Atoi(params[i+4])
+				i += 4
+			default:
+				out = append(out, p)
+				continue
+			}
+
+			if w.Depth == ColorDepth256 {
+				if n < 0 {
+					n = xterm256(r, g, b)
+				}
+				out = append(out, fmt.Sprintf("%d;5;%d", base, n))
+				continue
+			}
+
+			out = append(out, strconv.Itoa(ansi16Code(base, ansi16(r, g, b))))
+		}
+
+		return fmt.Sprintf("\033[%sm", strings.Join(out, ";"))
+	})
+}
+
+var ansi16Palette = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+var xtermLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// xtermRGB returns the RGB value of an xterm-256 color.
+func xtermRGB(n int) (int, int, int) {
+	switch {
+	case n < 0 || n > 255:
+		return 0, 0, 0
+	case n < 16:
+		c := ansi16Palette[n]
+		return c[0], c[1], c[2]
+	case n < 232:
+		n -= 16
+		return xtermLevels[n/36], xtermLevels[n/6%6], xtermLevels[n%6]
+	default:
+		v := 8 + (n-232)*10
+		return v, v, v
+	}
+}
+
+// xterm256 returns the closest xterm-256 color to an RGB value from the color
+// cube or the gray ramp.
+func xterm256(r, g, b int) int {
+	level := func(v int) int {
+		best := 0
+		for i, l := range xtermLevels {
+			if abs(v-l) < abs(v-xtermLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+
+	cube := 16 + 36*level(r) + 6*level(g) + level(b)
+
+	gray := 232 + min(max((r+g+b)/3-8+5, 0)/10, 23)
+
+	if colorDistance(r, g, b, gray) < colorDistance(r, g, b, cube) {
+		return gray
+	}
+
+	return cube
+}
+
+// ansi16 returns the index of the closest of the 16 basic colors.
+func ansi16(r, g, b int) int {
+	best := 0
+
+	for i := range ansi16Palette {
+		if colorDistance(r, g, b, i) < colorDistance(r, g, b, best) {
+			best = i
+		}
+	}
+
+	return best
+}
+
+func ansi16Code(base, i int) int {
+	offset := 30
+
+	if base == 48 {
+		offset = 40
+	}
+
+	if i >= 8 {
+		return offset + 60 + i - 8
+	}
+
+	return offset + i
+}
+
+func colorDistance(r, g, b, n int) int {
+	cr, cg, cb := xtermRGB(n)
+
+	return (r-cr)*(r-cr) + (g-cg)*(g-cg) + (b-cb)*(b-cb)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
@@ -0,0 +1,247 @@
+package stdcli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStyleRenderer(t *testing.T) {
+	tests := []struct {
+		name  string
+		style Style
+		want  string
+	}{
+		{"foreground", Style{Foreground: "214"}, "\033[38;5;214mtext\033[0m"},
+		{"background", Style{Background: "#102030"}, "\033[48;2;16;32;48mtext\033[0m"},
+		{"bold", Style{Bold: true, Foreground: "1"}, "\033[1m\033[38;5;1mtext\033[0m"},
+		{"italic", Style{Italic: true}, "\033[3mtext\033[23m"},
+		{"underline", Style{Underline: true}, "\033[4mtext\033[24m"},
+		{"invalid color", Style{Foreground: "blue"}, "text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.style.Renderer()("<tag>text</tag>"); got != tt.want {
+				t.Errorf("Renderer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThemeTags(t *testing.T) {
+	tags := Themes["dark"].Tags()
+
+	if _, ok := tags["error-label"]; ok {
+		t.Errorf("Tags() has error-label")
+	}
+
+	if got, want := tags["error"]("<error>failed</error>"), "\033[38;5;124mERROR: \033[38;5;203mfailed\033[0m"; got != want {
+		t.Errorf("error = %q, want %q", got, want)
+	}
+
+	for name := range Themes["dark"] {
+		if _, ok := Themes["light"][name]; !ok {
+			t.Errorf("light theme is missing %s", name)
+		}
+	}
+}
+
+func TestWriterSetTheme(t *testing.T) {
+	custom := func(s string) string { return "custom" }
+
+	w := &Writer{Color: true, Tags: map[string]Renderer{"custom": custom, "h1": custom}}
+
+	w.SetTheme(Theme{"h1": {Bold: true}})
+
+	if got, want := w.renderTags("<custom>x</custom> <h1>title</h1>"), "custom \033[1mtitle\033[22m"; got != want {
+		t.Errorf("renderTags() = %q, want %q", got, want)
+	}
+}
+
+func TestWriterDowngrade(t *testing.T) {
+	tests := []struct {
+		name  string
+		depth ColorDepth
+		in    string
+		want  string
+	}{
+		{"true color", ColorDepthTrue, "\033[38;2;255;135;0mx\033[0m", "\033[38;2;255;135;0mx\033[0m"},
+		{"rgb to 256", ColorDepth256, "\033[38;2;255;135;0mx\033[0m", "\033[38;5;208mx\033[0m"},
+		{"gray to 256", ColorDepth256, "\033[48;2;128;128;128mx", "\033[48;5;244mx"},
+		{"256 unchanged", ColorDepth256, "\033[38;5;214mx", "\033[38;5;214mx"},
+		{"256 to 16", ColorDepth16, "\033[38;5;160mx\033[0m", "\033[31mx\033[0m"},
+		{"bright to 16", ColorDepth16, "\033[38;5;46mx", "\033[92mx"},
+		{"background to 16", ColorDepth16, "\033[48;5;21mx", "\033[44mx"},
+		{"rgb to 16", ColorDepth16, "\033[38;2;250;250;250mx", "\033[97mx"},
+		{"mixed params", ColorDepth16, "\033[1;38;5;196;4mx", "\033[1;91;4mx"},
+		{"attributes", ColorDepth16, "\033[4mx\033[24m", "\033[4mx\033[24m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &Writer{Color: true, Depth: tt.depth}
+
+			if got := w.renderTags(tt.in); got != tt.want {
+				t.Errorf("renderTags() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvColor(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		want   bool
+		wantOK bool
+	}{
+		{"unset", map[string]string{}, false, false},
+		{"no color", map[string]string{"NO_COLOR": "1"}, false, true},
+		{"no color wins", map[string]string{"NO_COLOR": "1", "FORCE_COLOR": "1"}, false, true},
+		{"clicolor force", map[string]string{"CLICOLOR_FORCE": "1"}, true, true},
+		{"force color", map[string]string{"FORCE_COLOR": "3"}, true, true},
+		{"force color off", map[string]string{"FORCE_COLOR": "0"}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"NO_COLOR", "CLICOLOR_FORCE", "FORCE_COLOR"} {
+				t.Setenv(name, tt.env[name])
+			}
+
+			// an empty FORCE_COLOR still counts as set
+			if _, ok := tt.env["FORCE_COLOR"]; !ok {
+				unsetenv(t, "FORCE_COLOR")
+			}
+
+			if _, ok := tt.env["CLICOLOR_FORCE"]; !ok {
+				unsetenv(t, "CLICOLOR_FORCE")
+			}
+
+			got, ok := envColor()
+
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("envColor() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestEnvColorDepth(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want ColorDepth
+	}{
+		{"no term", map[string]string{}, ColorDepth256},
+		{"xterm", map[string]string{"TERM": "xterm"}, ColorDepth16},
+		{"xterm-256color", map[string]string{"TERM": "xterm-256color"}, ColorDepth256},
+		{"colorterm", map[string]string{"TERM": "xterm", "COLORTERM": "truecolor"}, ColorDepthTrue},
+		{"force color level", map[string]string{"TERM": "xterm-256color", "FORCE_COLOR": "1"}, ColorDepth16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"COLORTERM", "FORCE_COLOR", "TERM"} {
+				t.Setenv(name, tt.env[name])
+			}
+
+			if got := envColorDepth(); got != tt.want {
+				t.Errorf("envColorDepth() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectTheme(t *testing.T) {
+	tests := []struct {
+		colorfgbg string
+		want      string
+	}{
+		{"", "dark"},
+		{"15;0", "dark"},
+		{"0;15", "light"},
+		{"0;default;7", "light"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.colorfgbg, func(t *testing.T) {
+			t.Setenv("COLORFGBG", tt.colorfgbg)
+
+			if got, want := detectTheme()["h1"], Themes[tt.want]["h1"]; got != want {
+				t.Errorf("detectTheme() h1 = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestColorFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		color   bool
+		mode    string
+		env     string
+		want    string
+		wantErr string
+	}{
+		{name: "auto", args: []string{"test"}, want: "title\n"},
+		{name: "always", args: []string{"test", "--color", "always"}, want: "\033[38;5;244mtitle\033[0m\n"},
+		{name: "never", args: []string{"test", "--color=never"}, color: true, want: "title\n"},
+		{name: "no color", args: []string{"test"}, color: true, env: "1", want: "title\n"},
+		{name: "engine mode", args: []string{"test"}, mode: "always", env: "1", want: "\033[38;5;244mtitle\033[0m\n"},
+		{name: "flag overrides engine mode", args: []string{"test", "--color=never"}, mode: "always", want: "title\n"},
+		{name: "invalid", args: []string{"test", "--color=sometimes"}, wantErr: "ERROR: invalid color mode: sometimes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.env)
+			unsetenv(t, "CLICOLOR_FORCE")
+			unsetenv(t, "FORCE_COLOR")
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			e := &Engine{
+				ColorMode: tt.mode,
+				Name:      "testapp",
+				Version:   "1.0.0",
+				Writer: &Writer{
+					Color:  tt.color,
+					Stdout: stdout,
+					Stderr: stderr,
+					Tags:   Themes["dark"].Tags(),
+				},
+			}
+
+			e.Command("test", "test command", func(ctx Context) error {
+				ctx.Writef("<h1>title</h1>\n")
+				return nil
+			}, CommandOptions{})
+
+			e.ExecuteContext(context.Background(), tt.args)
+
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("stdout = %q, want %q", got, tt.want)
+			}
+
+			if !strings.Contains(stripColor(stderr.String()), tt.wantErr) {
+				t.Errorf("stderr = %q, want %q", stderr.String(), tt.wantErr)
+			}
+
+			if e.Writer.Color != tt.color {
+				t.Errorf("Color = %v after command, want %v", e.Writer.Color, tt.color)
+			}
+		})
+	}
+}
+
+// unsetenv removes name for the rest of the test.
+func unsetenv(t *testing.T, name string) {
+	t.Setenv(name, "")
+	os.Unsetenv(name) //nolint:errcheck
+}
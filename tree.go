@@ -0,0 +1,137 @@
+package stdcli
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+type TreeWriter interface {
+	Add(label string) TreeNode
+	Print() error
+}
+
+type TreeNode interface {
+	Add(label string) TreeNode
+}
+
+type treeWriter struct {
+	ctx   *defaultContext
+	nodes []*treeNode
+}
+
+// treeNode fields are ordered for the json output.
+type treeNode struct {
+	Label    string      `json:"label"`
+	Children []*treeNode `json:"children,omitempty"`
+}
+
+var (
+	_ TreeWriter = &treeWriter{}
+	_ TreeNode   = &treeNode{}
+)
+
+type treeBranches struct {
+	last   string
+	line   string
+	middle string
+	space  string
+}
+
+var (
+	asciiBranches   = treeBranches{last: "`-- ", line: "|   ", middle: "|-- ", space: "    "}
+	unicodeBranches = treeBranches{last: "└── ", line: "│   ", middle: "├── ", space: "    "}
+)
+
+// Tree returns a writer for a hierarchy of labels that is drawn with
+// box-drawing characters on a UTF-8 terminal and ASCII otherwise.
+func (c *defaultContext) Tree() TreeWriter {
+	return &treeWriter{ctx: c}
+}
+
+// Add adds a top level node.
+func (t *treeWriter) Add(label string) TreeNode {
+	n := &treeNode{Label: label}
+	t.nodes = append(t.nodes, n)
+	return n
+}
+
+func (t *treeWriter) Print() error {
+	if t.ctx.Flags().String("output") == "json" {
+		return t.printJSON()
+	}
+
+	return t.printText()
+}
+
+func (t *treeWriter) printJSON() error {
+	nodes := plainTree(t.nodes)
+
+	data, err := json.MarshalIndent(nodes, "", "  ")
+	if err != nil {
+		return err //nowrap
+	}
+
+	if _, err := t.ctx.Write(data); err != nil {
+		return err //nowrap
+	}
+
+	return nil
+}
+
+func (t *treeWriter) printText() error {
+	b := asciiBranches
+
+	if t.ctx.IsTerminalWriter() && isUTF8Locale() {
+		b = unicodeBranches
+	}
+
+	for _, n := range t.nodes {
+		t.ctx.Writef("%s\n", n.Label)
+		t.printChildren(n.Children, "", b)
+	}
+
+	return nil
+}
+
+func (t *treeWriter) printChildren(nodes []*treeNode, prefix string, b treeBranches) {
+	for i, n := range nodes {
+		branch, next := b.middle, b.line
+
+		if i == len(nodes)-1 {
+			branch, next = b.last, b.space
+		}
+
+		t.ctx.Writef("%s%s%s\n", prefix, branch, n.Label)
+		t.printChildren(n.Children, prefix+next, b)
+	}
+}
+
+// Add adds a child node.
+func (n *treeNode) Add(label string) TreeNode {
+	c := &treeNode{Label: label}
+	n.Children = append(n.Children, c)
+	return c
+}
+
+// plainTree copies nodes with tags stripped from their labels.
+func plainTree(nodes []*treeNode) []*treeNode {
+	plain := make([]*treeNode, len(nodes))
+
+	for i, n := range nodes {
+		plain[i] = &treeNode{Children: plainTree(n.Children), Label: stripTags(n.Label)}
+	}
+
+	return plain
+}
+
+func isUTF8Locale() bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			v = strings.ToLower(v)
+			return strings.Contains(v, "utf-8") || strings.Contains(v, "utf8")
+		}
+	}
+
+	return false
+}
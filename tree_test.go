@@ -0,0 +1,141 @@
+package stdcli
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func buildTree(tw TreeWriter) {
+	app := tw.Add("<id>myapp</id>")
+	web := app.Add("web")
+	web.Add("postgres")
+	web.Add("redis")
+	app.Add("worker").Add("redis")
+	tw.Add("other")
+}
+
+func TestTreeWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     string
+		terminal bool
+		want     []string
+	}{
+		{
+			name:     "unicode",
+			lang:     "en_US.UTF-8",
+			terminal: true,
+			want: []string{
+				"myapp",
+				"├── web",
+				"│   ├── postgres",
+				"│   └── redis",
+				"└── worker",
+				"    └── redis",
+				"other",
+			},
+		},
+		{
+			name:     "non utf-8 locale",
+			lang:     "C",
+			terminal: true,
+			want: []string{
+				"myapp",
+				"|-- web",
+				"|   |-- postgres",
+				"|   `-- redis",
+				"`-- worker",
+				"    `-- redis",
+				"other",
+			},
+		},
+		{
+			name: "not a terminal",
+			lang: "en_US.UTF-8",
+			want: []string{
+				"myapp",
+				"|-- web",
+				"|   |-- postgres",
+				"|   `-- redis",
+				"`-- worker",
+				"    `-- redis",
+				"other",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LC_ALL", "")
+			t.Setenv("LC_CTYPE", "")
+			t.Setenv("LANG", tt.lang)
+
+			var stdout interface {
+				io.Writer
+				String() string
+			} = &bytes.Buffer{}
+
+			if tt.terminal {
+				stdout = &testTerminal{height: 24, width: 80}
+			}
+
+			tw := testContext(stdout, stdout, nil).Tree()
+			buildTree(tw)
+
+			if err := tw.Print(); err != nil {
+				t.Fatalf("Print() error = %v", err)
+			}
+
+			if got, want := stripColor(stdout.String()), strings.Join(tt.want, "\n")+"\n"; got != want {
+				t.Errorf("output =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestTreeWriterJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	tw := testContext(buf, buf, map[string]string{"output": "json"}).Tree()
+	buildTree(tw)
+
+	if err := tw.Print(); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+
+	want := `[
+  {
+    "label": "myapp",
+    "children": [
+      {
+        "label": "web",
+        "children": [
+          {
+            "label": "postgres"
+          },
+          {
+            "label": "redis"
+          }
+        ]
+      },
+      {
+        "label": "worker",
+        "children": [
+          {
+            "label": "redis"
+          }
+        ]
+      }
+    ]
+  },
+  {
+    "label": "other"
+  }
+]`
+
+	if got := buf.String(); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
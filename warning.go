@@ -0,0 +1,101 @@
+package stdcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"go.ddollar.dev/errors"
+)
+
+type stderrWriter struct {
+	writer *Writer
+}
+
+// Stderr returns a writer for diagnostics that renders tags like Write but
+// keeps stdout clean for piped output.
+func (c *defaultContext) Stderr() io.Writer {
+	return stderrWriter{writer: c.engine.Writer}
+}
+
+func (s stderrWriter) Write(data []byte) (int, error) {
+	if _, err := io.WriteString(s.writer.Stderr, s.writer.renderTags(string(data))); err != nil {
+		return 0, errors.Wrap(err)
+	}
+
+	return len(data), nil
+}
+
+// Warnf records a warning. It is written to stderr right away unless the
+// engine has WarningSummary set, in which case all warnings are listed once
+// the command finishes.
+//
+// With --output json stdout only ever holds the result. Stderr holds, in
+// order, a one line {"event": "step", ...} object for each Step that
+// finishes, log records as text, anything the handler writes to Stderr and,
+// once the command finishes, an indented
+// {"error": ..., "warnings": [...]} object if it failed or {"warnings": [...]}
+// if it succeeded with warnings. The "warnings" key is omitted from errors
+// when there are none.
+func (c *defaultContext) Warnf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+
+	c.mu.Lock()
+	c.warnings = append(c.warnings, msg)
+	c.mu.Unlock()
+
+	if c.engine.WarningSummary || c.Flags().String("output") == "json" {
+		return
+	}
+
+	fmt.Fprintf(c.Stderr(), "<warning>WARNING:</warning> %s\n", EscapeTags(msg)) //nolint:errcheck
+}
+
+func (c *defaultContext) collectedWarnings() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string{}, c.warnings...)
+}
+
+// writeWarnings reports the warnings a command collected once it has
+// finished. JSON errors include their warnings, so they are only written here
+// when the command did not fail with one and stderr ends in a single object.
+func (e *Engine) writeWarnings(cc *defaultContext, err error) {
+	warnings := cc.collectedWarnings()
+
+	if len(warnings) == 0 {
+		return
+	}
+
+	if cc.Flags().String("output") == "json" {
+		if writesError(err) {
+			return
+		}
+
+		data, jerr := json.MarshalIndent(map[string]any{"warnings": warnings}, "", "  ")
+		if jerr != nil {
+			return
+		}
+
+		fmt.Fprintf(e.Writer.Stderr, "%s\n", data) //nolint:errcheck
+
+		return
+	}
+
+	if !e.WarningSummary {
+		return
+	}
+
+	noun := "warnings"
+
+	if len(warnings) == 1 {
+		noun = "warning"
+	}
+
+	fmt.Fprintf(cc.Stderr(), "<warning>%d %s:</warning>\n", len(warnings), noun) //nolint:errcheck
+
+	for _, w := range warnings {
+		fmt.Fprintf(cc.Stderr(), "  %s\n", EscapeTags(w)) //nolint:errcheck
+	}
+}
@@ -0,0 +1,186 @@
+package stdcli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func warningEngine(stdout, stderr *bytes.Buffer, summary bool) *Engine {
+	e := testEngine(stdout, stderr)
+	e.Flags = []Flag{StringFlag("output", "o", "output format")}
+	e.WarningSummary = summary
+
+	e.Command("test", "test command", func(ctx Context) error {
+		ctx.Warnf("disk is %d%% full", 91)
+		ctx.Writef("result\n")
+		ctx.Warnf("<value> is deprecated")
+		return nil
+	}, CommandOptions{})
+
+	e.Command("fail", "failing command", func(ctx Context) error {
+		ctx.Warnf("retrying")
+		return Errorf("failed").WithCode("E1")
+	}, CommandOptions{})
+
+	return e
+}
+
+func TestContextStderr(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	e := warningEngine(stdout, stderr, false)
+
+	e.Command("stderr", "stderr command", func(ctx Context) error {
+		fmt.Fprintf(ctx.Stderr(), "<h1>progress</h1> 50%%\n")
+		return nil
+	}, CommandOptions{})
+
+	if code := e.ExecuteContext(context.Background(), []string{"stderr"}); code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+
+	if stdout.Len() != 0 {
+		t.Errorf("stdout = %q, want empty", stdout.String())
+	}
+
+	if got, want := stderr.String(), "progress 50%\n"; got != want {
+		t.Errorf("stderr = %q, want %q", got, want)
+	}
+}
+
+func TestContextWarnf(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		summary    bool
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name:       "immediate",
+			args:       []string{"test"},
+			wantStdout: "result\n",
+			wantStderr: "WARNING: disk is 91% full\nWARNING: <value> is deprecated\n",
+		},
+		{
+			name:       "summary",
+			args:       []string{"test"},
+			summary:    true,
+			wantStdout: "result\n",
+			wantStderr: "2 warnings:\n  disk is 91% full\n  <value> is deprecated\n",
+		},
+		{
+			name:       "summary after error",
+			args:       []string{"fail"},
+			summary:    true,
+			wantCode:   1,
+			wantStderr: "ERROR: failed\n1 warning:\n  retrying\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			e := warningEngine(stdout, stderr, tt.summary)
+
+			if code := e.ExecuteContext(context.Background(), tt.args); code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d", code, tt.wantCode)
+			}
+
+			if got := stdout.String(); got != tt.wantStdout {
+				t.Errorf("stdout = %q, want %q", got, tt.wantStdout)
+			}
+
+			if got := stderr.String(); got != tt.wantStderr {
+				t.Errorf("stderr = %q, want %q", got, tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestContextWarnfJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantError bool
+	}{
+		{name: "result", args: []string{"test", "--output", "json"}},
+		{name: "error", args: []string{"fail", "--output", "json"}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			warningEngine(stdout, stderr, false).ExecuteContext(context.Background(), tt.args)
+
+			var got struct {
+				Error    *errorJSON `json:"error"`
+				Warnings []string   `json:"warnings"`
+			}
+
+			if err := json.Unmarshal(stderr.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal stderr: %v, output: %s", err, stderr.String())
+			}
+
+			if (got.Error != nil) != tt.wantError {
+				t.Errorf("error = %v, want error %v", got.Error, tt.wantError)
+			}
+
+			if len(got.Warnings) == 0 {
+				t.Errorf("warnings = %v, want warnings", got.Warnings)
+			}
+
+			if strings.Contains(stdout.String(), "warnings") {
+				t.Errorf("stdout = %q, want no warnings", stdout.String())
+			}
+		})
+	}
+}
+
+func TestContextWarnfJSONSteps(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	e := warningEngine(stdout, stderr, false)
+
+	e.Command("steps", "command with steps", func(ctx Context) error {
+		ctx.Step("deploying", func() error { return nil }) //nolint:errcheck
+		ctx.Warnf("slow")
+		return nil
+	}, CommandOptions{})
+
+	e.ExecuteContext(context.Background(), []string{"steps", "--output", "json"})
+
+	d := json.NewDecoder(stderr)
+
+	var step struct {
+		Event string `json:"event"`
+		Step  string `json:"step"`
+	}
+
+	if err := d.Decode(&step); err != nil || step.Event != "step" || step.Step != "deploying" {
+		t.Fatalf("first object = %+v, err = %v, want the step event", step, err)
+	}
+
+	var end struct {
+		Warnings []string `json:"warnings"`
+	}
+
+	if err := d.Decode(&end); err != nil || len(end.Warnings) != 1 {
+		t.Fatalf("last object = %+v, err = %v, want the warnings", end, err)
+	}
+
+	if d.More() {
+		t.Errorf("stderr has more after the warnings: %q", stderr.String())
+	}
+}
@@ -0,0 +1,59 @@
+package stdcli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const defaultWatchInterval = 2 * time.Second
+
+// watchFlag is added to commands that set CommandOptions.Watch.
+var watchFlag = Flag{
+	Description: "re-run every interval until interrupted (default 2s)",
+	Name:        "watch",
+	kind:        FlagDuration,
+	optional:    defaultWatchInterval.String(),
+}
+
+// watch runs the handler every interval until the context is canceled. On a
+// terminal each run redraws the alternate screen under a header, otherwise
+// output is appended. Each run gets its own context, so its cleanups run and
+// its warnings are written before the next one. Errors are shown and the next
+// run still happens.
+func (c *Command) watch(cc *defaultContext, interval time.Duration) error {
+	w := c.engine.Writer
+	terminal := w.IsTerminal()
+
+	if terminal {
+		fmt.Fprint(w.Stdout, "\033[?1049h")
+		defer fmt.Fprint(w.Stdout, "\033[?1049l")
+	}
+
+	for run := 0; ; run++ {
+		if terminal {
+			fmt.Fprint(w.Stdout, "\033[H\033[2J")
+		} else if run > 0 {
+			fmt.Fprint(w.Stdout, "\n")
+		}
+
+		cc.Writef("<h1>Every %s: %s %s</h1>  <info>%s</info>\n\n", interval, c.engine.Name, strings.Join(c.Command, " "), time.Now().Format(time.ANSIC))
+
+		rc := cc.fork()
+
+		err := c.Handler(rc)
+		if err != nil {
+			c.engine.handleError(rc, err)
+		}
+
+		c.engine.writeWarnings(rc, err)
+
+		rc.close()
+
+		select {
+		case <-cc.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
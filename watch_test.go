@@ -0,0 +1,171 @@
+package stdcli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"go.ddollar.dev/errors"
+)
+
+func watchEngine(stdout io.Writer, runs int, fail bool) (*Engine, context.Context, *int) {
+	ctx, cancel := context.WithCancel(context.Background())
+	count := 0
+
+	e := testEngine(stdout, stdout)
+
+	e.Command("status", "show status", func(c Context) error {
+		count++
+		c.Writef("run %d\n", count)
+		if count == runs {
+			cancel()
+		}
+		if fail {
+			return errors.Errorf("failed")
+		}
+		return nil
+	}, CommandOptions{Watch: true})
+
+	e.Command("once", "show status once", func(c Context) error {
+		count++
+		return nil
+	}, CommandOptions{})
+
+	return e, ctx, &count
+}
+
+func TestWatch(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	e, ctx, count := watchEngine(buf, 3, false)
+
+	if code := e.ExecuteContext(ctx, []string{"status", "--watch=1ms"}); code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+
+	if *count != 3 {
+		t.Errorf("runs = %d, want 3", *count)
+	}
+
+	got := buf.String()
+
+	if n := strings.Count(got, "Every 1ms: testapp status"); n != 3 {
+		t.Errorf("headers = %d, want 3 in %q", n, got)
+	}
+
+	if strings.Contains(got, "\033[") {
+		t.Errorf("output = %q, want no escape sequences off a terminal", got)
+	}
+
+	if !strings.Contains(got, "run 1\n\nEvery") || !strings.HasSuffix(got, "run 3\n") {
+		t.Errorf("output = %q, want appended runs", got)
+	}
+}
+
+func TestWatchTerminal(t *testing.T) {
+	stdout := &testTerminal{height: 24, width: 80}
+
+	e, ctx, _ := watchEngine(stdout, 2, false)
+
+	e.ExecuteContext(ctx, []string{"status", "--watch=1ms"})
+
+	got := stdout.String()
+
+	if !strings.HasPrefix(got, "\033[?1049h\033[H\033[2J") {
+		t.Errorf("output = %q, want alternate screen and clear", got)
+	}
+
+	if !strings.HasSuffix(got, "\033[?1049l") {
+		t.Errorf("output = %q, want alternate screen restored", got)
+	}
+
+	if n := strings.Count(got, "\033[H\033[2J"); n != 2 {
+		t.Errorf("redraws = %d, want 2", n)
+	}
+}
+
+func TestWatchErrors(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	e, ctx, count := watchEngine(buf, 2, true)
+
+	if code := e.ExecuteContext(ctx, []string{"status", "--watch=1ms"}); code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+
+	if *count != 2 {
+		t.Errorf("runs = %d, want 2", *count)
+	}
+
+	if n := strings.Count(buf.String(), "failed"); n != 2 {
+		t.Errorf("errors shown = %d, want 2 in %q", n, buf.String())
+	}
+}
+
+func TestWatchCleanup(t *testing.T) {
+	stdout := &testTerminal{height: 24, width: 80}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	e := testEngine(stdout, &bytes.Buffer{})
+
+	spinners := []*spinner{}
+	warnings := []int{}
+
+	e.Command("status", "show status", func(c Context) error {
+		if n := len(spinners); n > 0 {
+			select {
+			case <-spinners[n-1].done:
+			default:
+				t.Errorf("spinner from run %d still running", n)
+			}
+		}
+
+		spinners = append(spinners, c.Spinner("working").(*spinner))
+
+		c.Warnf("run %d", len(spinners))
+		warnings = append(warnings, len(c.(*defaultContext).collectedWarnings()))
+
+		if len(spinners) == 3 {
+			cancel()
+		}
+
+		return nil
+	}, CommandOptions{Watch: true})
+
+	e.ExecuteContext(ctx, []string{"status", "--watch=1ms"})
+
+	if len(spinners) != 3 {
+		t.Fatalf("runs = %d, want 3", len(spinners))
+	}
+
+	for i, n := range warnings {
+		if n != 1 {
+			t.Errorf("run %d warnings = %d, want 1", i+1, n)
+		}
+	}
+}
+
+func TestWatchDefaultAndOptIn(t *testing.T) {
+	e, _, count := watchEngine(&bytes.Buffer{}, 0, false)
+
+	if flags := e.globalFlags(&e.Commands[1]); !hasFlag(flags, "watch") {
+		t.Errorf("watch flag missing for opted in command")
+	}
+
+	if flags := e.globalFlags(&e.Commands[2]); hasFlag(flags, "watch") {
+		t.Errorf("watch flag added to command that did not opt in")
+	}
+
+	if code := e.ExecuteContext(context.Background(), []string{"once", "--watch"}); code == 0 {
+		t.Errorf("exit code = 0, want unknown flag error")
+	}
+
+	if *count != 0 {
+		t.Errorf("runs = %d, want 0", *count)
+	}
+
+}
@@ -0,0 +1,126 @@
+package stdcli
+
+import (
+	"regexp"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ansiMatcher = regexp.MustCompile("\033\\[[0-9;?]*[A-Za-z]")
+
+// wideRanges are the characters that take up two columns in a terminal: East
+// Asian wide and fullwidth characters and emoji.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f}, {0x231a, 0x231b}, {0x2329, 0x232a}, {0x23e9, 0x23ec},
+	{0x23f0, 0x23f0}, {0x23f3, 0x23f3}, {0x25fd, 0x25fe}, {0x2614, 0x2615},
+	{0x2648, 0x2653}, {0x267f, 0x267f}, {0x2693, 0x2693}, {0x26a1, 0x26a1},
+	{0x26aa, 0x26ab}, {0x26bd, 0x26be}, {0x26c4, 0x26c5}, {0x26ce, 0x26ce},
+	{0x26d4, 0x26d4}, {0x26ea, 0x26ea}, {0x26f2, 0x26f3}, {0x26f5, 0x26f5},
+	{0x26fa, 0x26fa}, {0x26fd, 0x26fd}, {0x2705, 0x2705}, {0x270a, 0x270b},
+	{0x2728, 0x2728}, {0x274c, 0x274c}, {0x274e, 0x274e}, {0x2753, 0x2755},
+	{0x2757, 0x2757}, {0x2795, 0x2797}, {0x27b0, 0x27b0}, {0x27bf, 0x27bf},
+	{0x2b1b, 0x2b1c}, {0x2b50, 0x2b50}, {0x2b55, 0x2b55}, {0x2e80, 0x303e},
+	{0x3041, 0x33ff}, {0x3400, 0x4dbf}, {0x4e00, 0x9fff}, {0xa000, 0xa4cf},
+	{0xa960, 0xa97f}, {0xac00, 0xd7a3}, {0xf900, 0xfaff}, {0xfe10, 0xfe19},
+	{0xfe30, 0xfe6f}, {0xff00, 0xff60}, {0xffe0, 0xffe6}, {0x1f004, 0x1f004},
+	{0x1f0cf, 0x1f0cf}, {0x1f18e, 0x1f18e}, {0x1f191, 0x1f19a}, {0x1f200, 0x1f251},
+	{0x1f300, 0x1f64f}, {0x1f680, 0x1f6ff}, {0x1f7e0, 0x1f7eb}, {0x1f900, 0x1f9ff},
+	{0x1fa70, 0x1faff}, {0x20000, 0x2fffd}, {0x30000, 0x3fffd},
+}
+
+const (
+	zeroWidthJoiner = 0x200d
+	emojiSelector   = 0xfe0f
+)
+
+// textWidth returns the number of terminal columns v takes up once its tags
+// and ANSI escape sequences are stripped.
+func textWidth(v any) int {
+	return stringWidth(visibleText(v))
+}
+
+// visibleText strips tags and ANSI escape sequences from v.
+func visibleText(v any) string {
+	return stripANSI(stripTags(v))
+}
+
+func stripANSI(s string) string {
+	return ansiMatcher.ReplaceAllString(s, "")
+}
+
+// stringWidth returns the number of terminal columns s takes up.
+func stringWidth(s string) int {
+	w := 0
+
+	for i := 0; i < len(s); {
+		size, cw := nextCluster(s[i:])
+		w += cw
+		i += size
+	}
+
+	return w
+}
+
+// nextCluster returns the length in bytes and the width in columns of the
+// character at the start of s along with any combining marks, variation
+// selectors, skin tones and joined emoji that follow it.
+func nextCluster(s string) (int, int) {
+	r, size := utf8.DecodeRuneInString(s)
+	width := runeWidth(r)
+
+	// a pair of regional indicators is a single flag
+	if isRegional(r) {
+		if next, n := utf8.DecodeRuneInString(s[size:]); isRegional(next) {
+			size += n
+		}
+		return size, 2
+	}
+
+	for size < len(s) {
+		next, n := utf8.DecodeRuneInString(s[size:])
+
+		switch {
+		case next == zeroWidthJoiner:
+			size += n
+			if size < len(s) {
+				_, n = utf8.DecodeRuneInString(s[size:])
+				size += n
+			}
+		case next == emojiSelector:
+			size += n
+			if width > 0 {
+				width = 2
+			}
+		case unicode.In(next, unicode.Mn, unicode.Me), next >= 0x1f3fb && next <= 0x1f3ff:
+			size += n
+		default:
+			return size, width
+		}
+	}
+
+	return size, width
+}
+
+func isRegional(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+func runeWidth(r rune) int {
+	switch {
+	case r < 0x20, r >= 0x7f && r < 0xa0:
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	}
+
+	i := sort.Search(len(wideRanges), func(i int) bool {
+		return wideRanges[i][1] >= r
+	})
+
+	if i < len(wideRanges) && wideRanges[i][0] <= r {
+		return 2
+	}
+
+	return 1
+}
@@ -0,0 +1,134 @@
+package stdcli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want int
+	}{
+		{"ascii", "hello", 5},
+		{"empty", "", 0},
+		{"accented", "héllo", 5},
+		{"combining", "héllo", 5},
+		{"cjk", "日本語", 6},
+		{"hangul", "한국어", 6},
+		{"fullwidth", "ＡＢ", 4},
+		{"emoji", "🚀", 2},
+		{"emoji presentation", "❤️", 2},
+		{"text presentation", "❤", 1},
+		{"zwj sequence", "👩‍👩‍👧", 2},
+		{"skin tone", "👍🏽", 2},
+		{"flag", "🇯🇵", 2},
+		{"mixed", "a日b🚀c", 7},
+		{"ansi", "\033[38;5;208mhello\033[0m", 5},
+		{"tags", "<h1>日本</h1>", 4},
+		{"tags and ansi", "<value>\033[4mdocs\033[24m</value>", 4},
+		{"control", "a\tb", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := textWidth(tt.s); got != tt.want {
+				t.Errorf("textWidth(%q) = %d, want %d", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCutTextWide(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		n        int
+		wantHead string
+		wantTail string
+	}{
+		{"cjk", "日本語", 4, "日本", "語"},
+		{"cjk mid character", "日本語", 3, "日", "本語"},
+		{"combining stays attached", "éé", 1, "é", "é"},
+		{"zwj sequence", "👩‍👩‍👧x", 2, "👩‍👩‍👧", "x"},
+		{"ansi", "\033[1mbold\033[0m", 2, "\033[1mbo\033[0m", "\033[1mld\033[0m"},
+		{"ansi reset before cut", "\033[1mab\033[0mcd", 3, "\033[1mab\033[0mc", "d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			head, tail := cutText(tt.s, tt.n)
+
+			if head != tt.wantHead || tail != tt.wantTail {
+				t.Errorf("cutText(%q, %d) = %q, %q, want %q, %q", tt.s, tt.n, head, tail, tt.wantHead, tt.wantTail)
+			}
+		})
+	}
+}
+
+func TestColumnWriterWideCharacters(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	ctx := &defaultContext{
+		Context: context.Background(),
+		engine: &Engine{
+			Writer: &Writer{Stdout: buf, Stderr: buf, Tags: DefaultWriter.Tags},
+		},
+	}
+
+	cw := ctx.Columns()
+	cw.Append("NAME", "REGION", "STATUS")
+	cw.Append("東京", "ap-northeast-1", "🟢 ok")
+	cw.Append("café", "eu-west-1", "ok")
+	cw.Append("café", "us-east-1", "ok")
+	cw.Append(DefaultWriter.Sprintf("<id>web</id>"), "us-west-2", "ok")
+
+	if err := cw.Print(); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+
+	want := []string{
+		"NAME  REGION          STATUS",
+		"東京  ap-northeast-1  🟢 ok",
+		"café  eu-west-1       ok",
+		"café  us-east-1       ok",
+		"web   us-west-2       ok",
+	}
+
+	got := strings.Split(strings.TrimSuffix(stripColor(buf.String()), "\n"), "\n")
+
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("output =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestInfoWriterWideHeaders(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	outputFlag := StringFlag("output", "", "output format")
+
+	ctx := &defaultContext{
+		Context: context.Background(),
+		flags:   Flags{&outputFlag},
+		engine: &Engine{
+			Writer: &Writer{Stdout: buf, Stderr: buf, Tags: DefaultWriter.Tags},
+		},
+	}
+
+	iw := ctx.Info()
+	iw.Add("名前", "web")
+	iw.Add("Status", "running")
+
+	if err := iw.Print(); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+
+	want := "名前    web\nSTATUS  running\n"
+
+	if got := stripColor(buf.String()); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
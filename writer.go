@@ -1,10 +1,13 @@
 package stdcli
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
 
 	"go.ddollar.dev/errors"
@@ -18,6 +21,7 @@ type Renderer func(string) string
 
 type Writer struct {
 	Color  bool
+	Depth  ColorDepth
 	Stdout io.Writer
 	Stderr io.Writer
 	Tags   map[string]Renderer
@@ -26,47 +30,78 @@ type Writer struct {
 func init() {
 	DefaultWriter = &Writer{
 		Color:  isTerminal(os.Stdout),
+		Depth:  envColorDepth(),
 		Stdout: os.Stdout,
 		Stderr: os.Stderr,
-		Tags: map[string]Renderer{
-			"error":  renderError,
-			"header": RenderColors(242),
-			"h1":     RenderColors(244),
-			"h2":     RenderColors(241),
-			"id":     RenderColors(247),
-			"info":   RenderColors(247),
-			"ok":     RenderColors(46),
-			"start":  RenderColors(247),
-			"u":      RenderUnderline(),
-			"value":  RenderColors(251),
-		},
+		Tags:   detectTheme().Tags(),
+	}
+
+	if v, ok := envColor(); ok {
+		DefaultWriter.Color = v
 	}
 }
 
 func (w *Writer) Error(err error) error {
+	return w.error(err, w.logger(envLogLevel()))
+}
+
+func (w *Writer) error(err error, logger *slog.Logger) error {
 	fmt.Fprintf(w.Stderr, w.renderTags("<error>%s</error>\n"), err)
 
-	if os.Getenv("DEBUG") == "true" {
-		if serr, ok := err.(errors.ErrorTracer); ok {
-			for _, f := range serr.ErrorTrace() {
-				fmt.Fprintf(w.Stderr, w.renderTags("<info>  %s:%d</info>\n"), f, f)
-			}
+	if e, ok := errors.Cause(err).(*Error); ok {
+		for _, h := range e.Hints {
+			fmt.Fprintf(w.Stderr, w.renderTags("  <info>hint:</info> <value>%s</value>\n"), h)
+		}
+
+		if e.URL != "" {
+			fmt.Fprintf(w.Stderr, w.renderTags("  <info>docs:</info> <u>%s</u>\n"), e.URL)
+		}
+	}
+
+	if serr, ok := err.(errors.ErrorTracer); ok {
+		for _, f := range serr.ErrorTrace() {
+			logger.Debug("trace", "frame", fmt.Sprintf("%s:%d", f, f))
 		}
 	}
 
 	return err //nowrap
 }
 
+// ErrorJSON writes err to stderr as a JSON object for machine consumption.
+func (w *Writer) ErrorJSON(err error) error {
+	return w.errorJSON(err, nil)
+}
+
+// errorJSON writes err along with any warnings collected before it.
+func (w *Writer) errorJSON(err error, warnings []string) error {
+	v := map[string]any{"error": newErrorJSON(err)}
+
+	if len(warnings) > 0 {
+		v["warnings"] = warnings
+	}
+
+	data, jerr := json.MarshalIndent(v, "", "  ")
+	if jerr != nil {
+		return errors.Wrap(jerr)
+	}
+
+	if _, werr := fmt.Fprintf(w.Stderr, "%s\n", data); werr != nil {
+		return errors.Wrap(werr)
+	}
+
+	return err //nowrap
+}
+
 func (w *Writer) Errorf(format string, args ...any) error {
 	return w.Error(errors.Errorf(format, args...))
 }
 
 func (w *Writer) IsTerminal() bool {
-	if f, ok := w.Stdout.(*os.File); ok {
-		return isTerminal(f)
-	}
+	return isTerminalStream(w.Stdout)
+}
 
-	return false
+func (w *Writer) logger(level slog.Leveler) *slog.Logger {
+	return slog.New(newTagHandler(w, level))
 }
 
 func (w *Writer) Sprintf(format string, args ...any) string {
@@ -91,16 +126,237 @@ func (w *Writer) Writef(format string, args ...any) (int, error) {
 	return n, nil
 }
 
+// renderTags renders the writer's tags in a single pass. Tags can be nested
+// and an inner tag that resets the style restores the outer one. Unknown tags
+// are left as is, \< writes a literal < and \\ writes a literal \ in a run
+// of backslashes before a <.
 func (w *Writer) renderTags(s string) string {
-	for tag, render := range w.Tags {
-		s = regexp.MustCompile(fmt.Sprintf("<%s>(.*?)</%s>", tag, tag)).ReplaceAllStringFunc(s, render)
+	if strings.IndexByte(s, '<') >= 0 {
+		s = w.parseTags(s)
 	}
 
 	if !w.Color {
-		s = stripColor(s)
+		return stripColor(s)
+	}
+
+	return w.downgrade(s)
+}
+
+// tagFrame is an open tag whose contents start at start in the output.
+type tagFrame struct {
+	name  string
+	start int
+}
+
+func (w *Writer) parseTags(s string) string {
+	out := make([]byte, 0, len(s))
+	stack := []tagFrame{}
+	codes := map[string]string{}
+
+	for i := 0; i < len(s); {
+		if c, n := tagEscape(s[i:]); n > 0 {
+			out = append(out, c)
+			i += n
+			continue
+		}
+
+		if s[i] == '<' {
+			name, closing, n := scanTag(s[i:])
+
+			if _, ok := w.Tags[name]; ok && n > 0 && !closing {
+				stack = append(stack, tagFrame{name: name, start: len(out)})
+				i += n
+				continue
+			}
+
+			if j := openTag(stack, name); n > 0 && closing && j >= 0 {
+				out = unwindTags(out, stack[j+1:])
+				out = w.renderTag(out, stack[j], codes)
+				stack = stack[:j]
+				i += n
+				continue
+			}
+		}
+
+		out = append(out, s[i])
+		i++
+	}
+
+	return string(unwindTags(out, stack))
+}
+
+// renderTag replaces the contents of f at the end of out with the rendered
+// tag.
+func (w *Writer) renderTag(out []byte, f tagFrame, codes map[string]string) []byte {
+	content := string(out[f.start:])
+
+	if c := w.tagCodes(f.name, codes); c != "" {
+		content = restoreStyle(content, c)
+	}
+
+	return append(out[:f.start], w.Tags[f.name]("<"+f.name+">"+content+"</"+f.name+">")...)
+}
+
+// tagCodes returns the escape codes a tag opens with, found by rendering a
+// placeholder.
+func (w *Writer) tagCodes(name string, codes map[string]string) string {
+	if c, ok := codes[name]; ok {
+		return c
+	}
+
+	sample := w.Tags[name]("<" + name + ">\x00</" + name + ">")
+
+	c := ""
+
+	if i := strings.IndexByte(sample, 0); i >= 0 {
+		for _, seq := range ansiMatcher.FindAllString(sample[:i], -1) {
+			if strings.HasSuffix(seq, "m") {
+				c += seq
+			}
+		}
+	}
+
+	codes[name] = c
+
+	return c
+}
+
+// restoreStyle reapplies codes after each reset in s that is followed by
+// more text.
+func restoreStyle(s, codes string) string {
+	if !strings.Contains(s, "\033[") {
+		return s
+	}
+
+	b := strings.Builder{}
+
+	for {
+		i, n := strings.Index(s, "\033[0m"), 4
+
+		if j := strings.Index(s, "\033[m"); j >= 0 && (i < 0 || j < i) {
+			i, n = j, 3
+		}
+
+		if i < 0 || i+n == len(s) {
+			b.WriteString(s)
+			return b.String()
+		}
+
+		b.WriteString(s[:i+n])
+		b.WriteString(codes)
+		s = s[i+n:]
+	}
+}
+
+// scanTag reads a tag like <name> or </name> at the start of s and returns
+// its name, whether it closes and its length, which is 0 if s does not start
+// with a tag.
+func scanTag(s string) (string, bool, int) {
+	i := 1
+	closing := len(s) > 1 && s[1] == '/'
+
+	if closing {
+		i++
+	}
+
+	start := i
+
+	for i < len(s) && isTagChar(s[i]) {
+		i++
+	}
+
+	if i == start || i >= len(s) || s[i] != '>' {
+		return "", false, 0
+	}
+
+	return s[start:i], closing, i + 1
+}
+
+func isTagChar(c byte) bool {
+	return c == '-' || c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// openTag returns the index of the innermost open tag named name or -1.
+func openTag(stack []tagFrame, name string) int {
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// unwindTags writes the opening tags of frames that were never closed back
+// into out.
+func unwindTags(out []byte, frames []tagFrame) []byte {
+	for i := len(frames) - 1; i >= 0; i-- {
+		out = slices.Insert(out, frames[i].start, []byte("<"+frames[i].name+">")...)
+	}
+
+	return out
+}
+
+// EscapeTags escapes s so that it is written literally instead of being
+// rendered as tags. Each < becomes \< and backslashes before one are doubled.
+// Backslashes at the end of s are left as is, so an escaped value that is
+// followed by a closing tag should not end in one.
+func EscapeTags(s string) string {
+	return tagEscaper.ReplaceAllStringFunc(s, func(m string) string {
+		return m[:len(m)-1] + m[:len(m)-1] + `\<`
+	})
+}
+
+// tagEscape returns the byte written by the escape at the start of s and its
+// length, which is 0 if s does not start with one. Backslashes only escape in
+// a run that ends in a <, so paths and json are written as is.
+func tagEscape(s string) (byte, int) {
+	if len(s) < 2 || s[0] != '\\' {
+		return 0, 0
+	}
+
+	switch {
+	case s[1] == '<':
+		return '<', 2
+	case s[1] == '\\' && strings.HasPrefix(strings.TrimLeft(s, `\`), "<"):
+		return '\\', 2
+	default:
+		return 0, 0
+	}
+}
+
+// escapedAt returns true if the < at i in s is escaped by an odd run of
+// backslashes.
+func escapedAt(s string, i int) bool {
+	return (i-len(strings.TrimRight(s[:i], `\`)))%2 == 1
+}
+
+// unescapeTags replaces the escapes in s with the text they write.
+func unescapeTags(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	b := strings.Builder{}
+
+	for i := 0; i < len(s); {
+		if c, n := tagEscape(s[i:]); n > 0 {
+			b.WriteByte(c)
+			i += n
+			continue
+		}
+
+		b.WriteByte(s[i])
+		i++
 	}
 
-	return s
+	return b.String()
+}
+
+// wrapTag wraps s in the tag name, doubling any trailing backslashes so that
+// they do not escape the closing tag.
+func wrapTag(name, s string) string {
+	return "<" + name + ">" + s + s[len(strings.TrimRight(s, `\`)):] + "</" + name + ">"
 }
 
 func RenderColors(colors ...int) Renderer {
@@ -119,13 +375,9 @@ func RenderUnderline() Renderer {
 	}
 }
 
-func renderError(s string) string {
-	return fmt.Sprintf("\033[38;5;124mERROR: \033[38;5;203m%s\033[0m", stripTag(s))
-}
-
 var (
 	colorStripper = regexp.MustCompile("\033\\[[^m]+m")
-	tagStripper   = regexp.MustCompile(`^<[^>?]+>(.*)</[^>?]+>$`)
+	tagEscaper    = regexp.MustCompile(`\\*<`)
 	tagMatcher    = regexp.MustCompile(`<([^>?]+)>`)
 )
 
@@ -134,41 +386,77 @@ func stripColor(s string) string {
 }
 
 func stripTag(v any) string {
-	s := fmt.Sprintf("%v", v)
+	s, ok := v.(string)
+	if !ok {
+		s = fmt.Sprintf("%v", v)
+	}
 
-	match := tagStripper.FindStringSubmatch(s)
+	// matches ^<[^>?]+>(.*)</[^>?]+>$ without a regexp as every rendered tag
+	// goes through here
+	open := strings.IndexByte(s, '>')
+	closer := strings.LastIndex(s, "</")
+
+	if !strings.HasPrefix(s, "<") || !strings.HasSuffix(s, ">") || open < 2 || closer <= open || closer+3 >= len(s) {
+		return s
+	}
 
-	if len(match) != 2 {
+	if strings.ContainsRune(s[1:open], '?') || strings.ContainsAny(s[closer+2:len(s)-1], ">?") {
 		return s
 	}
 
-	return match[1]
+	return s[open+1 : closer]
+}
+
+// stripTags removes tag pairs from v, leaving escaped tags as literal text.
+// indexCloser returns the index of the first closer in s from i that is not
+// escaped, or -1.
+func indexCloser(s, closer string, i int) int {
+	for {
+		j := strings.Index(s[i:], closer)
+		if j < 0 {
+			return -1
+		}
+
+		if i += j; !escapedAt(s, i) {
+			return i
+		}
+
+		i += len(closer)
+	}
 }
 
 func stripTags(v any) string {
 	s := fmt.Sprintf("%v", v)
+	i := 0
 
 	for {
-		m := tagMatcher.FindStringSubmatchIndex(s)
+		m := tagMatcher.FindStringSubmatchIndex(s[i:])
 
 		if len(m) != 4 {
 			break
 		}
 
-		os := m[0]
-		oe := m[1]
+		os := i + m[0]
+		oe := i + m[1]
+
+		if escapedAt(s, os) {
+			i = oe
+			continue
+		}
 
-		closer := fmt.Sprintf("</%s>", s[m[2]:m[3]])
-		cs := strings.Index(s, closer)
+		closer := fmt.Sprintf("</%s>", s[i+m[2]:i+m[3]])
+		cs := indexCloser(s, closer, oe)
 
 		if cs == -1 {
-			break
+			i = oe
+			continue
 		}
 
 		ce := cs + len(closer)
 
 		s = s[:os] + s[oe:cs] + s[ce:]
+		i = os
 	}
 
-	return s
+	return unescapeTags(s)
 }
@@ -2,6 +2,8 @@ package stdcli
 
 import (
 	"bytes"
+	"fmt"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -329,6 +331,16 @@ func TestStripTags(t *testing.T) {
 			input: "<h1><value>nested</value></h1>",
 			want:  "nested",
 		},
+		{
+			name:  "escaped tag",
+			input: `\<h1>literal</h1> <h1>tag</h1>`,
+			want:  "<h1>literal</h1> tag",
+		},
+		{
+			name:  "unclosed tag before pair",
+			input: "a <b> c <h1>d</h1>",
+			want:  "a <b> c d",
+		},
 	}
 
 	for _, tt := range tests {
@@ -381,3 +393,141 @@ func TestRenderUnderline(t *testing.T) {
 		t.Errorf("RenderUnderline() output should contain 'test'")
 	}
 }
+
+func TestWriterRenderTags(t *testing.T) {
+	tags := map[string]Renderer{
+		"b":     Style{Bold: true}.Renderer(),
+		"green": RenderColors(2),
+		"red":   RenderColors(1),
+		"u":     RenderUnderline(),
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", "hello", "hello"},
+		{"tag", "<red>x</red>", "\033[38;5;1mx\033[0m"},
+		{"nested attribute", "<red>a<u>b</u>c</red>", "\033[38;5;1ma\033[4mb\033[24mc\033[0m"},
+		{"nested reset", "<red>a<green>b</green>c</red>", "\033[38;5;1ma\033[38;5;2mb\033[0m\033[38;5;1mc\033[0m"},
+		{"nested three deep", "<b><red>a<green>b</green>c</red>d</b>", "\033[1m\033[38;5;1ma\033[38;5;2mb\033[0m\033[1m\033[38;5;1mc\033[0m\033[1md\033[22m"},
+		{"same tag nested", "<red>a<red>b</red>c</red>", "\033[38;5;1ma\033[38;5;1mb\033[0m\033[38;5;1mc\033[0m"},
+		{"inner reset at end", "<red><green>b</green></red>", "\033[38;5;1m\033[38;5;2mb\033[0m\033[0m"},
+		{"escaped", `\<red>x\</red>`, "<red>x</red>"},
+		{"escaped inside tag", `<red>\<u></red>`, "\033[38;5;1m<u>\033[0m"},
+		{"escaped backslash before closer", `<red>C:\\</red>`, "\033[38;5;1mC:\\\033[0m"},
+		{"escaped backslash and tag", `\\\<red>x`, `\<red>x`},
+		{"backslashes not before a tag", `\\server\share <red>x</red>`, "\\\\server\\share \033[38;5;1mx\033[0m"},
+		{"unknown tag", "<foo>x</foo> <red>y</red>", "<foo>x</foo> \033[38;5;1my\033[0m"},
+		{"unclosed", "<red>x", "<red>x"},
+		{"unclosed inside", "<red><u>x</red>", "\033[38;5;1m<u>x\033[0m"},
+		{"closer without opener", "x</red>", "x</red>"},
+		{"multiple lines", "<red>a\nb</red>", "\033[38;5;1ma\nb\033[0m"},
+		{"not a tag", "a < b > c <>", "a < b > c <>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &Writer{Color: true, Tags: tags}
+
+			if got := w.renderTags(tt.input); got != tt.want {
+				t.Errorf("renderTags() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeTags(t *testing.T) {
+	tests := []string{
+		"<h1>x</h1> <value>",
+		`C:\`,
+		`C:\\`,
+		`\<h1>x\\</h1>`,
+		`\\server\share`,
+	}
+
+	for _, color := range []bool{false, true} {
+		w := &Writer{Color: color, Tags: DefaultWriter.Tags}
+
+		for _, input := range tests {
+			if got := w.renderTags(EscapeTags(input)); got != input {
+				t.Errorf("renderTags(EscapeTags(%q)) = %q", input, got)
+			}
+
+			if got := stripTags(EscapeTags(input)); got != input {
+				t.Errorf("stripTags(EscapeTags(%q)) = %q", input, got)
+			}
+
+			if got := stripColor(w.renderTags(wrapTag("value", EscapeTags(input)))); got != input {
+				t.Errorf("renderTags(wrapTag(EscapeTags(%q))) = %q", input, got)
+			}
+		}
+	}
+}
+
+// benchmarkTable is a large table as columnWriter writes it.
+func benchmarkTable() string {
+	b := strings.Builder{}
+
+	b.WriteString("<h1>ID    NAME          STATUS   CREATED</h1>\n")
+
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&b, "<value><id>%05d</id>  service-%04d  <ok>running</ok>  %d minutes ago</value>\n", i, i, i%60)
+	}
+
+	return b.String()
+}
+
+// regexpRenderTags is the previous implementation, kept to compare against.
+func regexpRenderTags(w *Writer, s string) string {
+	for tag, render := range w.Tags {
+		s = regexp.MustCompile(fmt.Sprintf("<%s>(.*?)</%s>", tag, tag)).ReplaceAllStringFunc(s, render)
+	}
+
+	if !w.Color {
+		s = stripColor(s)
+	}
+
+	return s
+}
+
+func BenchmarkRenderTags(b *testing.B) {
+	w := &Writer{Color: true, Tags: DefaultWriter.Tags}
+	table := benchmarkTable()
+
+	b.SetBytes(int64(len(table)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		w.renderTags(table)
+	}
+}
+
+func BenchmarkRenderTagsRegexp(b *testing.B) {
+	w := &Writer{Color: true, Tags: DefaultWriter.Tags}
+	table := benchmarkTable()
+
+	b.SetBytes(int64(len(table)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		regexpRenderTags(w, table)
+	}
+}
+
+func BenchmarkRenderTagsLine(b *testing.B) {
+	w := &Writer{Color: true, Tags: DefaultWriter.Tags}
+
+	for i := 0; i < b.N; i++ {
+		w.renderTags("<value><id>00042</id>  service-0042  <ok>running</ok>  5 minutes ago</value>\n")
+	}
+}
+
+func BenchmarkRenderTagsLineRegexp(b *testing.B) {
+	w := &Writer{Color: true, Tags: DefaultWriter.Tags}
+
+	for i := 0; i < b.N; i++ {
+		regexpRenderTags(w, "<value><id>00042</id>  service-0042  <ok>running</ok>  5 minutes ago</value>\n")
+	}
+}